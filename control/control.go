@@ -0,0 +1,132 @@
+package control
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/window"
+	"math"
+	"time"
+)
+
+// Input is the keyboard and mouse state controllers react to. A
+// *window.Window is an input.
+type Input interface {
+	KeyDown(k window.Key) bool
+	MouseDown(b window.MouseButton) bool
+	CursorPos() (float64, float64)
+	Scroll() (float64, float64)
+}
+
+// Controller moves a camera according to input.
+type Controller interface {
+
+	// Update updates the camera with the input after time dt has passed.
+	Update(in Input, dt time.Duration)
+}
+
+// Keys are the keys to move with.
+type Keys struct {
+	Forward, Back, Left, Right window.Key
+	Up, Down                   window.Key
+	RollLeft, RollRight        window.Key
+}
+
+// DefKeys returns the default keys: W, S, A and D to move forward, back, left
+// and right, space and left shift to move up and down, Q and E to roll left
+// and right.
+func DefKeys() Keys {
+	return Keys{
+		Forward:   window.KeyW,
+		Back:      window.KeyS,
+		Left:      window.KeyA,
+		Right:     window.KeyD,
+		Up:        window.KeySpace,
+		Down:      window.KeyLeftShift,
+		RollLeft:  window.KeyQ,
+		RollRight: window.KeyE,
+	}
+}
+
+// axis returns 1 if key pos is down, -1 if key neg is down and 0 if both or
+// none are down.
+func axis(in Input, pos, neg window.Key) float64 {
+	a := 0.0
+	if in.KeyDown(pos) {
+		a++
+	}
+	if in.KeyDown(neg) {
+		a--
+	}
+	return a
+}
+
+// cursor tracks the movement of the mouse cursor between updates.
+type cursor struct {
+	x, y  float64
+	valid bool
+}
+
+// delta returns how far the cursor moved since the last call. The first call
+// returns no movement.
+func (c *cursor) delta(in Input) (float64, float64) {
+	x, y := in.CursorPos()
+	dx, dy := x-c.x, y-c.y
+	if !c.valid {
+		dx, dy = 0, 0
+	}
+	c.x, c.y, c.valid = x, y, true
+	return dx, dy
+}
+
+// follow returns the fraction by which a smoothed value approaches its goal
+// within dt, for smoothing time s in seconds. Without smoothing the goal is
+// reached immediately. After s the value has covered about 63% of the
+// distance.
+func follow(s float64, dt time.Duration) float64 {
+	if s <= 0 {
+		return 1
+	}
+	return 1 - math.Exp(-dt.Seconds()/s)
+}
+
+// approach moves v towards goal by fraction k.
+func approach(v *float64, goal, k float64) {
+	*v += (goal - *v) * k
+}
+
+// approachVec moves v towards goal by fraction k.
+func approachVec(v *geom.Vec3, goal *geom.Vec3, k float64) {
+	for i := range v {
+		approach(&v[i], goal[i], k)
+	}
+}
+
+// rotate rotates v by angle a around axis.
+func rotate(v *geom.Vec3, axis *geom.Vec3, a float64) {
+	r := render.RotTransf(axis, a).Transf(geom.NewVec4(v[0], v[1], v[2]))
+	*v = geom.Vec3{r[0], r[1], r[2]}
+}
+
+// dirVec returns the unit vector pointing in the direction given by yaw and
+// pitch. Yaw 0 and pitch 0 point along -z, positive yaw turns left and
+// positive pitch turns up.
+func dirVec(yaw, pitch float64) *geom.Vec3 {
+	cp := math.Cos(pitch)
+	return &geom.Vec3{-cp * math.Sin(yaw), math.Sin(pitch), -cp * math.Cos(yaw)}
+}
+
+// clampPitch limits pitch to just below straight up and down, where the view
+// would flip.
+func clampPitch(p float64) float64 {
+	max := math.Pi/2 - 0.001
+	return math.Max(-max, math.Min(max, p))
+}
+
+// look sets the camera at eye looking in direction dir with the given up
+// direction.
+func look(c *render.Camera, eye, dir, up *geom.Vec3) {
+	c.Eye = *eye
+	c.At = *eye
+	c.At.Add(dir)
+	c.Up = *up
+}
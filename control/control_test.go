@@ -0,0 +1,174 @@
+package control
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/window"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeInput is input set directly by tests.
+type fakeInput struct {
+	keys    map[window.Key]bool
+	buttons map[window.MouseButton]bool
+	x, y    float64
+	scroll  float64
+}
+
+func newFakeInput() *fakeInput {
+	return &fakeInput{
+		keys:    make(map[window.Key]bool),
+		buttons: make(map[window.MouseButton]bool),
+	}
+}
+
+func (f *fakeInput) KeyDown(k window.Key) bool {
+	return f.keys[k]
+}
+
+func (f *fakeInput) MouseDown(b window.MouseButton) bool {
+	return f.buttons[b]
+}
+
+func (f *fakeInput) CursorPos() (float64, float64) {
+	return f.x, f.y
+}
+
+func (f *fakeInput) Scroll() (float64, float64) {
+	return 0, f.scroll
+}
+
+// near returns true if v and w differ by less than 1e-9 in all components.
+func near(v, w *geom.Vec3) bool {
+	for i := range v {
+		if math.Abs(v[i]-w[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// lookDir returns the normalized direction the camera looks at.
+func lookDir(c *render.Camera) *geom.Vec3 {
+	_, _, z := c.CamAxes()
+	z.Neg()
+	return z
+}
+
+func TestFollow(t *testing.T) {
+	if k := follow(0, time.Second); k != 1 {
+		t.Errorf("expected '%v' but got '%v'", 1, k)
+	}
+	k := follow(1, time.Second)
+	if math.Abs(k-(1-1/math.E)) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", 1-1/math.E, k)
+	}
+}
+
+func TestOrbit(t *testing.T) {
+	c := render.NewDefCam()
+	o := NewOrbit(c)
+	o.Smoothing = 0
+	in := newFakeInput()
+	o.Update(in, time.Second)
+	if !near(&c.Eye, &geom.Vec3{0, 0, 5}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Vec3{0, 0, 5}, c.Eye)
+	}
+	// Drag a quarter turn to the left
+	in.buttons[window.MouseLeft] = true
+	in.x = -math.Pi / 2 / o.TurnSpeed
+	o.Update(in, time.Second)
+	if !near(&c.Eye, &geom.Vec3{5, 0, 0}) || !near(&c.At, &geom.Vec3{0, 0, 0}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Vec3{5, 0, 0}, c.Eye)
+	}
+	// Zoom in one step
+	in.buttons[window.MouseLeft] = false
+	in.scroll = 1
+	o.Update(in, time.Second)
+	if math.Abs(c.Eye[0]-4.5) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", 4.5, c.Eye[0])
+	}
+}
+
+func TestOrbitPitch(t *testing.T) {
+	c := render.NewDefCam()
+	o := NewOrbit(c)
+	o.Smoothing = 0
+	o.Pitch = 0.5
+	o.Update(newFakeInput(), time.Second)
+	if c.Eye[1] <= 0 {
+		t.Errorf("expected eye above target but got '%v'", c.Eye)
+	}
+	if math.Abs(c.Eye[1]-5*math.Sin(0.5)) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", 5*math.Sin(0.5), c.Eye[1])
+	}
+}
+
+func TestOrbitSmoothing(t *testing.T) {
+	c := render.NewDefCam()
+	o := NewOrbit(c)
+	o.Smoothing = 1
+	in := newFakeInput()
+	o.Update(in, time.Second)
+	o.Dist = 10
+	o.Update(in, time.Second)
+	// Moved 63% of the way from 5 to 10
+	should := 5 + 5*(1-1/math.E)
+	if math.Abs(c.Eye[2]-should) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", should, c.Eye[2])
+	}
+}
+
+func TestFly(t *testing.T) {
+	c := render.NewDefCam()
+	f := NewFly(c)
+	f.Smoothing = 0
+	in := newFakeInput()
+	f.Update(in, time.Second)
+	// Turn left by a quarter and move forward, ends up at -x
+	in.x = -math.Pi / 2 / f.TurnSpeed
+	in.keys[window.KeyW] = true
+	f.Update(in, time.Second)
+	if !near(&c.Eye, &geom.Vec3{-2, 0, 0}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Vec3{-2, 0, 0}, c.Eye)
+	}
+	if d := lookDir(c); !near(d, &geom.Vec3{-1, 0, 0}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Vec3{-1, 0, 0}, *d)
+	}
+	// Roll left by a quarter, up is then along +z
+	in.keys[window.KeyW] = false
+	in.keys[window.KeyQ] = true
+	f.Update(in, time.Duration(math.Pi/2/f.RollSpeed*float64(time.Second)))
+	_, up, _ := c.CamAxes()
+	if !near(up, &geom.Vec3{0, 0, 1}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Vec3{0, 0, 1}, *up)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	c := render.NewDefCam()
+	c.Eye = geom.Vec3{0, 1.5, 0}
+	c.At = geom.Vec3{0, 1.5, -1}
+	w := NewWalk(c)
+	w.Smoothing = 0
+	in := newFakeInput()
+	w.Update(in, time.Second)
+	// Look up and walk forward, still at the same height
+	in.y = -0.5 / w.TurnSpeed
+	in.keys[window.KeyW] = true
+	w.Update(in, time.Second)
+	if !near(&c.Eye, &geom.Vec3{0, 1.5, -2}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Vec3{0, 1.5, -2}, c.Eye)
+	}
+	if d := lookDir(c); math.Abs(d[1]-math.Sin(0.5)) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", math.Sin(0.5), d[1])
+	}
+	// Can't look beyond straight up
+	in.y -= 10 / w.TurnSpeed
+	w.Update(in, time.Second)
+	if w.Pitch >= math.Pi/2 {
+		t.Errorf("expected pitch below '%v' but got '%v'", math.Pi/2, w.Pitch)
+	}
+}
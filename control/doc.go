@@ -0,0 +1,12 @@
+// Package control provides camera controllers that move and turn a
+// render.Camera from keyboard and mouse input of a window:
+//
+//   - Orbit circles around a target point, zooms and pans
+//   - Fly moves freely in all directions, looks around with the mouse and
+//     rolls
+//   - Walk moves on the ground at eye height and looks around like in a first
+//     person game
+//
+// Controllers are updated once per frame with the time passed, e.g. from the
+// Update function of a loop.Loop, and set the camera's Eye, At and Up.
+package control
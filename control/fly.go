@@ -0,0 +1,100 @@
+package control
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"time"
+)
+
+// Fly moves a camera freely in all directions relative to where it looks.
+// Moving the mouse turns the camera around its own up and right axis, the
+// roll keys turn it around the looking direction. There is no fixed up
+// direction, so after rolling the camera stays tilted.
+type Fly struct {
+
+	// Camera to control
+	Camera *render.Camera
+
+	// Keys to move and roll with
+	Keys Keys
+
+	// Speed is the distance per second when moving.
+	Speed float64
+
+	// TurnSpeed is the angle in radians per pixel of mouse movement.
+	TurnSpeed float64
+
+	// RollSpeed is the angle in radians per second when rolling.
+	RollSpeed float64
+
+	// Smoothing is the time in seconds the camera takes to mostly follow the
+	// input. If 0 the camera follows immediately.
+	Smoothing float64
+
+	// Current velocity
+	vel geom.Vec3
+
+	// Turns around the up, right and looking axis not yet done
+	turn [3]float64
+
+	cursor cursor
+}
+
+// NewFly returns a new fly controller for camera c with the default keys,
+// speed 2 and slight smoothing.
+func NewFly(c *render.Camera) *Fly {
+	return &Fly{
+		Camera:    c,
+		Keys:      DefKeys(),
+		Speed:     2,
+		TurnSpeed: 0.003,
+		RollSpeed: 1,
+		Smoothing: 0.05,
+	}
+}
+
+// Update updates the camera with the input after time dt has passed.
+func (f *Fly) Update(in Input, dt time.Duration) {
+	c := f.Camera
+	x, y, z := c.CamAxes()
+	dx, dy := f.cursor.delta(in)
+	f.turn[0] -= dx * f.TurnSpeed
+	f.turn[1] -= dy * f.TurnSpeed
+	f.turn[2] += axis(in, f.Keys.RollLeft, f.Keys.RollRight) * f.RollSpeed * dt.Seconds()
+	k := follow(f.Smoothing, dt)
+	var t [3]float64
+	for i := range t {
+		t[i] = f.turn[i] * k
+		f.turn[i] -= t[i]
+	}
+	// Looking direction is -z
+	fwd := *z
+	fwd.Neg()
+	rotate(&fwd, y, t[0])
+	rotate(x, y, t[0])
+	rotate(&fwd, x, t[1])
+	rotate(y, x, t[1])
+	rotate(y, &fwd, -t[2])
+	x = geom.Cross(&fwd, y)
+	goal := geom.Vec3{}
+	for _, m := range []struct {
+		dir *geom.Vec3
+		a   float64
+	}{
+		{&fwd, axis(in, f.Keys.Forward, f.Keys.Back)},
+		{x, axis(in, f.Keys.Right, f.Keys.Left)},
+		{y, axis(in, f.Keys.Up, f.Keys.Down)},
+	} {
+		d := *m.dir
+		d.Scale(m.a)
+		goal.Add(&d)
+	}
+	goal.Norm()
+	goal.Scale(f.Speed)
+	approachVec(&f.vel, &goal, k)
+	eye := c.Eye
+	d := f.vel
+	d.Scale(dt.Seconds())
+	eye.Add(&d)
+	look(c, &eye, &fwd, y)
+}
@@ -0,0 +1,108 @@
+package control
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/window"
+	"math"
+	"time"
+)
+
+// Orbit moves a camera on a sphere around a target point, always looking at
+// the target. Dragging with the left mouse button turns around the target,
+// dragging with the right mouse button moves the target sideways and up or
+// down, and scrolling zooms in and out.
+type Orbit struct {
+
+	// Camera to control
+	Camera *render.Camera
+
+	// Target is the point to look at and turn around.
+	Target geom.Vec3
+
+	// Dist is the distance of the camera from the target.
+	Dist float64
+
+	// Yaw is the angle around the y axis in radians. At 0 the camera is on
+	// the positive z side of the target.
+	Yaw float64
+
+	// Pitch is the angle above the target's horizontal plane in radians.
+	Pitch float64
+
+	// TurnSpeed is the angle in radians per pixel of mouse movement.
+	TurnSpeed float64
+
+	// PanSpeed is the distance the target moves per pixel of mouse movement,
+	// as fraction of Dist.
+	PanSpeed float64
+
+	// ZoomSpeed is the fraction by which the distance changes per scroll
+	// step.
+	ZoomSpeed float64
+
+	// MinDist and MaxDist limit the distance.
+	MinDist, MaxDist float64
+
+	// Smoothing is the time in seconds the camera takes to mostly follow the
+	// input. If 0 the camera follows immediately.
+	Smoothing float64
+
+	// Smoothed state that follows the goal values above
+	target           geom.Vec3
+	dist, yaw, pitch float64
+	started          bool
+	cursor           cursor
+}
+
+// NewOrbit returns a new orbit controller for camera c around the origin at
+// distance 5, with slight smoothing.
+func NewOrbit(c *render.Camera) *Orbit {
+	return &Orbit{
+		Camera:    c,
+		Dist:      5,
+		TurnSpeed: 0.005,
+		PanSpeed:  0.002,
+		ZoomSpeed: 0.1,
+		MinDist:   0.1,
+		MaxDist:   1000,
+		Smoothing: 0.05,
+	}
+}
+
+// Update updates the camera with the input after time dt has passed.
+func (o *Orbit) Update(in Input, dt time.Duration) {
+	dx, dy := o.cursor.delta(in)
+	if in.MouseDown(window.MouseLeft) {
+		o.Yaw -= dx * o.TurnSpeed
+		o.Pitch += dy * o.TurnSpeed
+	}
+	o.Pitch = clampPitch(o.Pitch)
+	if in.MouseDown(window.MouseRight) {
+		x, y, _ := o.Camera.CamAxes()
+		s := o.PanSpeed * o.Dist
+		x.Scale(-dx * s)
+		y.Scale(dy * s)
+		o.Target.Add(x)
+		o.Target.Add(y)
+	}
+	_, sy := in.Scroll()
+	o.Dist *= math.Pow(1-o.ZoomSpeed, sy)
+	o.Dist = math.Max(o.MinDist, math.Min(o.MaxDist, o.Dist))
+	k := follow(o.Smoothing, dt)
+	if !o.started {
+		k = 1
+		o.started = true
+	}
+	approachVec(&o.target, &o.Target, k)
+	approach(&o.dist, o.Dist, k)
+	approach(&o.yaw, o.Yaw, k)
+	approach(&o.pitch, o.Pitch, k)
+	// Positive pitch looks down at the target from above
+	dir := dirVec(o.yaw, -o.pitch)
+	eye := *dir
+	eye.Scale(-o.dist)
+	eye.Add(&o.target)
+	dir.Scale(o.dist)
+	look(o.Camera, &eye, dir, &geom.Vec3{0, 1, 0})
+}
@@ -0,0 +1,93 @@
+package control
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"math"
+	"time"
+)
+
+// Walk moves a camera on the ground like in a first person game. Moving
+// forward and sideways stays horizontal regardless of looking up or down, and
+// the eyes are always at the same height. Moving the mouse turns left and
+// right and looks up and down, but never beyond straight up or down.
+type Walk struct {
+
+	// Camera to control
+	Camera *render.Camera
+
+	// Keys to move with. Up, down and roll keys are not used.
+	Keys Keys
+
+	// Height is the y coordinate of the eyes.
+	Height float64
+
+	// Yaw is the angle around the y axis in radians. At 0 the camera looks
+	// along -z, positive angles turn left.
+	Yaw float64
+
+	// Pitch is the angle above the horizon in radians.
+	Pitch float64
+
+	// Speed is the distance per second when walking.
+	Speed float64
+
+	// TurnSpeed is the angle in radians per pixel of mouse movement.
+	TurnSpeed float64
+
+	// Smoothing is the time in seconds the camera takes to mostly follow the
+	// input. If 0 the camera follows immediately.
+	Smoothing float64
+
+	// Current velocity, smoothed angles and cursor
+	vel        geom.Vec3
+	yaw, pitch float64
+	started    bool
+	cursor     cursor
+}
+
+// NewWalk returns a new walk controller for camera c with the default keys,
+// speed 2 and slight smoothing. It starts where the camera is, looking in the
+// same direction.
+func NewWalk(c *render.Camera) *Walk {
+	_, _, z := c.CamAxes()
+	return &Walk{
+		Camera:    c,
+		Keys:      DefKeys(),
+		Height:    c.Eye[1],
+		Yaw:       math.Atan2(z[0], z[2]),
+		Pitch:     clampPitch(math.Asin(-z[1])),
+		Speed:     2,
+		TurnSpeed: 0.003,
+		Smoothing: 0.05,
+	}
+}
+
+// Update updates the camera with the input after time dt has passed.
+func (w *Walk) Update(in Input, dt time.Duration) {
+	dx, dy := w.cursor.delta(in)
+	w.Yaw -= dx * w.TurnSpeed
+	w.Pitch = clampPitch(w.Pitch - dy*w.TurnSpeed)
+	k := follow(w.Smoothing, dt)
+	if !w.started {
+		w.yaw, w.pitch = w.Yaw, w.Pitch
+		w.started = true
+	}
+	approach(&w.yaw, w.Yaw, k)
+	approach(&w.pitch, w.Pitch, k)
+	fwd := dirVec(w.yaw, 0)
+	right := geom.Vec3{-fwd[2], 0, fwd[0]}
+	goal := *fwd
+	goal.Scale(axis(in, w.Keys.Forward, w.Keys.Back))
+	right.Scale(axis(in, w.Keys.Right, w.Keys.Left))
+	goal.Add(&right)
+	goal.Norm()
+	goal.Scale(w.Speed)
+	approachVec(&w.vel, &goal, k)
+	eye := w.Camera.Eye
+	d := w.vel
+	d.Scale(dt.Seconds())
+	eye.Add(&d)
+	eye[1] = w.Height
+	look(w.Camera, &eye, dirVec(w.yaw, w.pitch), &geom.Vec3{0, 1, 0})
+}
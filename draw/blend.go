@@ -0,0 +1,134 @@
+package draw
+
+import (
+	stdimage "image"
+	"image/color"
+	stddraw "image/draw"
+	"math"
+)
+
+// Op is a compositing operator that combines a source color being drawn with
+// the destination color already there. Colors are premultiplied with alpha as
+// in the standard library. In the descriptions below S and D are the source
+// and destination color, Sa and Da their alpha.
+type Op int
+
+const (
+	// Over draws the source over the destination: S + D*(1-Sa). This is the
+	// default for all drawing.
+	Over Op = iota
+
+	// Src replaces the destination with the source: S.
+	Src
+
+	// In keeps the source only where the destination is: S*Da.
+	In
+
+	// Out keeps the source only where the destination is not: S*(1-Da).
+	Out
+
+	// Atop draws the source over the destination only where the destination
+	// is: S*Da + D*(1-Sa).
+	Atop
+
+	// Xor keeps source and destination where they don't overlap:
+	// S*(1-Da) + D*(1-Sa).
+	Xor
+
+	// Add adds source and destination, limited to 1: min(1, S+D).
+	Add
+
+	// Multiply multiplies source and destination, which darkens:
+	// S*D + S*(1-Da) + D*(1-Sa).
+	Multiply
+
+	// Screen inverts, multiplies and inverts again, which lightens:
+	// S + D - S*D.
+	Screen
+)
+
+// composite returns the result of the operator for one channel with source s
+// and destination d, and source and destination alpha sa and da. All values
+// are in [0,1].
+func (op Op) composite(s, d, sa, da float64) float64 {
+	switch op {
+	case Src:
+		return s
+	case In:
+		return s * da
+	case Out:
+		return s * (1 - da)
+	case Atop:
+		return s*da + d*(1-sa)
+	case Xor:
+		return s*(1-da) + d*(1-sa)
+	case Add:
+		return math.Min(1, s+d)
+	case Multiply:
+		return s*d + s*(1-da) + d*(1-sa)
+	case Screen:
+		return s + d - s*d
+	}
+	return s + d*(1-sa)
+}
+
+// Composite returns the result of drawing color src with coverage cov in
+// [0,1] onto color dst. The operator is applied fully and the result is mixed
+// with dst by coverage, so parts of the destination outside a drawn shape stay
+// unchanged for all operators.
+func (op Op) Composite(src, dst color.Color, cov float64) color.Color {
+	sr, sg, sb, sa := src.RGBA()
+	dr, dg, db, da := dst.RGBA()
+	s := [4]float64{float64(sr), float64(sg), float64(sb), float64(sa)}
+	d := [4]float64{float64(dr), float64(dg), float64(db), float64(da)}
+	cov = math.Max(0, math.Min(1, cov))
+	var r [4]uint16
+	for i := range r {
+		v := op.composite(s[i]/0xffff, d[i]/0xffff, s[3]/0xffff, d[3]/0xffff)
+		v = d[i] + cov*(v*0xffff-d[i])
+		r[i] = uint16(math.Max(0, math.Min(0xffff, v+0.5)))
+	}
+	return color.RGBA64{r[0], r[1], r[2], r[3]}
+}
+
+// Blender is an image that composites everything set on it onto Dst with Op.
+// All drawing functions of this package composite with Over, drawing on a
+// blender wrapping the target uses a different operator instead.
+type Blender struct {
+
+	// Dst is the image drawn on.
+	Dst stddraw.Image
+
+	// Op is the operator used for drawing.
+	Op Op
+}
+
+// NewBlender returns a new blender drawing on dst with operator op.
+func NewBlender(dst stddraw.Image, op Op) *Blender {
+	return &Blender{dst, op}
+}
+
+// ColorModel returns the color model of the destination.
+func (b *Blender) ColorModel() color.Model {
+	return b.Dst.ColorModel()
+}
+
+// Bounds returns the bounds of the destination.
+func (b *Blender) Bounds() stdimage.Rectangle {
+	return b.Dst.Bounds()
+}
+
+// At returns the color of pixel (x,y) of the destination.
+func (b *Blender) At(x, y int) color.Color {
+	return b.Dst.At(x, y)
+}
+
+// Set composites color c onto pixel (x,y) of the destination.
+func (b *Blender) Set(x, y int, c color.Color) {
+	blend(b, x, y, c, 1)
+}
+
+// Pixel composites color c onto pixel (x,y).
+func Pixel(dst stddraw.Image, x, y int, c color.Color) {
+	blend(dst, x, y, c, 1)
+}
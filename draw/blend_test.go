@@ -0,0 +1,60 @@
+package draw
+
+import (
+	"image/color"
+	"testing"
+)
+
+var (
+	// Half transparent red and opaque blue
+	red  = color.RGBA{128, 0, 0, 128}
+	blue = color.RGBA{0, 0, 255, 255}
+)
+
+var compositetests = []struct {
+	op       Op
+	src, dst color.Color
+	cov      float64
+	res      color.RGBA
+}{
+	{Over, red, blue, 1, color.RGBA{128, 0, 127, 255}},
+	{Over, blue, red, 1, blue},
+	{Over, red, blue, 0.5, color.RGBA{64, 0, 191, 255}},
+	{Src, red, blue, 1, red},
+	{In, blue, red, 1, color.RGBA{0, 0, 128, 128}},
+	{In, blue, color.Transparent, 1, color.RGBA{}},
+	{Out, blue, red, 1, color.RGBA{0, 0, 127, 127}},
+	{Atop, red, blue, 1, color.RGBA{128, 0, 127, 255}},
+	{Atop, blue, color.Transparent, 1, color.RGBA{}},
+	{Xor, blue, red, 1, color.RGBA{0, 0, 127, 127}},
+	{Add, red, red, 1, color.RGBA{255, 0, 0, 255}},
+	{Multiply, color.White, blue, 1, blue},
+	{Multiply, color.RGBA{128, 128, 128, 255}, blue, 1, color.RGBA{0, 0, 128, 255}},
+	{Screen, red, blue, 1, color.RGBA{128, 0, 255, 255}},
+}
+
+func TestComposite(t *testing.T) {
+	for _, test := range compositetests {
+		res := color.RGBAModel.Convert(test.op.Composite(test.src, test.dst, test.cov))
+		if res != test.res {
+			t.Errorf("expected '%v' but got '%v' for op '%v'", test.res, res, test.op)
+		}
+	}
+}
+
+func TestBlender(t *testing.T) {
+	img := newRgba(10, 10)
+	p := NewPath()
+	p.Rect(0, 0, 5, 10)
+	Fill(img, p, blue, NonZero, false)
+	// Adding red everywhere only changes red
+	p = NewPath()
+	p.Rect(0, 0, 10, 10)
+	Fill(NewBlender(img, Add), p, red, NonZero, false)
+	if c := img.RGBAAt(2, 2); c != (color.RGBA{128, 0, 255, 255}) {
+		t.Errorf("expected '%v' but got '%v'", color.RGBA{128, 0, 255, 255}, c)
+	}
+	if c := img.RGBAAt(7, 2); c != (color.RGBA{128, 0, 0, 255}) {
+		t.Errorf("expected '%v' but got '%v'", color.RGBA{128, 0, 0, 255}, c)
+	}
+}
@@ -0,0 +1,13 @@
+// Package draw provides 2D drawing of lines and shapes. It draws on any image
+// that implements draw.Image from the standard library, so the same functions
+// work for the RGBA image of an image.Image as well as for the canvas of a
+// window.Window.
+//
+// Lines are drawn directly with Line, LineAa and Polyline. Other shapes are
+// built as a Path of lines, Bézier curves and arcs, and then drawn with Fill
+// or Outline.
+//
+// Coordinates are in pixels with (0,0) at the top left corner of the top left
+// pixel, so the center of pixel (x,y) is at (x+0.5,y+0.5). Functions taking
+// integer coordinates address whole pixels.
+package draw
@@ -0,0 +1,33 @@
+package draw
+
+import (
+	stdimage "image"
+	"image/color"
+	stddraw "image/draw"
+)
+
+// Point is a position in pixel coordinates.
+type Point [2]float64
+
+// blend composites color c onto pixel (x,y) with coverage cov in [0,1]. The
+// operator is Over, or the operator of dst if it is a blender. Pixels outside
+// of dst are ignored.
+func blend(dst stddraw.Image, x, y int, c color.Color, cov float64) {
+	op := Over
+	if b, ok := dst.(*Blender); ok {
+		op = b.Op
+		dst = b.Dst
+	}
+	if !(stdimage.Point{x, y}).In(dst.Bounds()) || cov <= 0 {
+		return
+	}
+	if cov >= 1 && op == Src {
+		dst.Set(x, y, c)
+		return
+	}
+	if _, _, _, a := c.RGBA(); cov >= 1 && op == Over && a == 0xffff {
+		dst.Set(x, y, c)
+		return
+	}
+	dst.Set(x, y, op.Composite(c, dst.At(x, y), cov))
+}
@@ -0,0 +1,136 @@
+package draw
+
+import (
+	"image/color"
+	stddraw "image/draw"
+	"math"
+	"sort"
+)
+
+// subScanlines is the number of scanlines sampled per pixel row when filling
+// with anti-aliasing.
+const subScanlines = 4
+
+// crossing is where a polygon edge crosses a scanline, with the direction of
+// the edge: +1 downwards and -1 upwards.
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// byX sorts crossings from left to right.
+type byX []crossing
+
+func (c byX) Len() int           { return len(c) }
+func (c byX) Less(i, j int) bool { return c[i].x < c[j].x }
+func (c byX) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// addSpan adds weight w to the coverage of the pixels between x0 and x1.
+// Pixels only partly between get the covered fraction of w. Cov starts at
+// pixel x = 0.
+func addSpan(cov []float64, x0, x1, w float64) {
+	x0 = math.Max(0, x0)
+	x1 = math.Min(float64(len(cov)), x1)
+	if x1 <= x0 {
+		return
+	}
+	i0 := int(x0)
+	i1 := int(x1)
+	if i0 == i1 {
+		cov[i0] += (x1 - x0) * w
+		return
+	}
+	cov[i0] += (float64(i0+1) - x0) * w
+	for i := i0 + 1; i < i1; i++ {
+		cov[i] += w
+	}
+	if i1 < len(cov) {
+		cov[i1] += (x1 - float64(i1)) * w
+	}
+}
+
+// fill fills closed polygons with color c. Polygons are closed implicitly
+// from the last to the first point. If evenOdd is true a point is inside if a
+// ray from it crosses edges an odd number of times, otherwise if the edges
+// wind around it a non-zero number of times. With aa the coverage of pixels
+// at the edges is approximated by several scanlines per pixel row and exact
+// horizontal coverage, otherwise pixels are filled if their center is inside.
+func fill(dst stddraw.Image, polys [][]Point, c color.Color, evenOdd, aa bool) {
+	b := dst.Bounds()
+	minx, miny := math.Inf(1), math.Inf(1)
+	maxx, maxy := math.Inf(-1), math.Inf(-1)
+	for _, p := range polys {
+		for _, v := range p {
+			minx = math.Min(minx, v[0])
+			maxx = math.Max(maxx, v[0])
+			miny = math.Min(miny, v[1])
+			maxy = math.Max(maxy, v[1])
+		}
+	}
+	x0 := int(math.Max(float64(b.Min.X), math.Floor(minx)))
+	x1 := int(math.Min(float64(b.Max.X), math.Ceil(maxx)))
+	y0 := int(math.Max(float64(b.Min.Y), math.Floor(miny)))
+	y1 := int(math.Min(float64(b.Max.Y), math.Ceil(maxy)))
+	if x1 <= x0 || y1 <= y0 {
+		return
+	}
+	n := 1
+	if aa {
+		n = subScanlines
+	}
+	cov := make([]float64, x1-x0)
+	var cross []crossing
+	for py := y0; py < y1; py++ {
+		for i := range cov {
+			cov[i] = 0
+		}
+		for s := 0; s < n; s++ {
+			sy := float64(py) + (float64(s)+0.5)/float64(n)
+			cross = cross[:0]
+			for _, p := range polys {
+				for i := range p {
+					a := p[i]
+					e := p[(i+1)%len(p)]
+					if a[1] == e[1] {
+						continue
+					}
+					dir := 1
+					if a[1] > e[1] {
+						a, e = e, a
+						dir = -1
+					}
+					if sy < a[1] || sy >= e[1] {
+						continue
+					}
+					x := a[0] + (sy-a[1])*(e[0]-a[0])/(e[1]-a[1])
+					cross = append(cross, crossing{x, dir})
+				}
+			}
+			sort.Sort(byX(cross))
+			wind := 0
+			for i := 0; i < len(cross)-1; i++ {
+				wind += cross[i].dir
+				in := wind != 0
+				if evenOdd {
+					in = wind%2 != 0
+				}
+				if !in {
+					continue
+				}
+				xa := cross[i].x - float64(x0)
+				xb := cross[i+1].x - float64(x0)
+				if aa {
+					addSpan(cov, xa, xb, 1/float64(n))
+				} else {
+					// Pixels with their center in the span
+					addSpan(cov, math.Ceil(xa-0.5), math.Ceil(xb-0.5), 1)
+				}
+			}
+		}
+		for i, v := range cov {
+			if v > 0 {
+				blend(dst, x0+i, py, c, math.Min(1, v))
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package draw
+
+import (
+	"image/color"
+	stddraw "image/draw"
+	"math"
+)
+
+// Line draws a 1 pixel thick line from pixel (x1,y1) to pixel (x2,y2)
+// including both ends. It uses Bresenham's algorithm with integer arithmetic
+// only, so the same pixels are drawn regardless of direction and slope.
+func Line(dst stddraw.Image, x1, y1, x2, y2 int, c color.Color) {
+	dx := x2 - x1
+	sx := 1
+	if dx < 0 {
+		dx = -dx
+		sx = -1
+	}
+	dy := y2 - y1
+	sy := 1
+	if dy < 0 {
+		dy = -dy
+		sy = -1
+	}
+	// err is the difference between the error terms for x and y, kept doubled
+	// to avoid fractions
+	err := dx - dy
+	for {
+		blend(dst, x1, y1, c, 1)
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x1 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y1 += sy
+		}
+	}
+}
+
+// fpart returns the fractional part of f.
+func fpart(f float64) float64 {
+	return f - math.Floor(f)
+}
+
+// LineAa draws an anti-aliased line about 1 pixel wide between (x1,y1) and
+// (x2,y2) with Xiaolin Wu's algorithm. For every step along the major axis the
+// two pixels closest to the line are drawn, with coverage according to their
+// distance to the line.
+func LineAa(dst stddraw.Image, x1, y1, x2, y2 float64, c color.Color) {
+	// Work in pixel center coordinates
+	x1 -= 0.5
+	y1 -= 0.5
+	x2 -= 0.5
+	y2 -= 0.5
+	steep := math.Abs(y2-y1) > math.Abs(x2-x1)
+	plot := func(x, y int, cov float64) {
+		if steep {
+			x, y = y, x
+		}
+		blend(dst, x, y, c, cov)
+	}
+	if steep {
+		x1, y1 = y1, x1
+		x2, y2 = y2, x2
+	}
+	if x1 > x2 {
+		x1, x2 = x2, x1
+		y1, y2 = y2, y1
+	}
+	dx := x2 - x1
+	dy := y2 - y1
+	grad := 1.0
+	if dx != 0 {
+		grad = dy / dx
+	}
+	// First end point, covered according to how much of its pixel the line
+	// spans along the major axis
+	xend := math.Floor(x1 + 0.5)
+	yend := y1 + grad*(xend-x1)
+	xgap := 1 - fpart(x1+0.5)
+	xpx1 := int(xend)
+	ypx1 := int(math.Floor(yend))
+	plot(xpx1, ypx1, (1-fpart(yend))*xgap)
+	plot(xpx1, ypx1+1, fpart(yend)*xgap)
+	intery := yend + grad
+	// Second end point
+	xend = math.Floor(x2 + 0.5)
+	yend = y2 + grad*(xend-x2)
+	xgap = fpart(x2 + 0.5)
+	xpx2 := int(xend)
+	ypx2 := int(math.Floor(yend))
+	if xpx2 == xpx1 {
+		return
+	}
+	plot(xpx2, ypx2, (1-fpart(yend))*xgap)
+	plot(xpx2, ypx2+1, fpart(yend)*xgap)
+	for x := xpx1 + 1; x < xpx2; x++ {
+		y := int(math.Floor(intery))
+		plot(x, y, 1-fpart(intery))
+		plot(x, y+1, fpart(intery))
+		intery += grad
+	}
+}
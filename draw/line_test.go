@@ -0,0 +1,88 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+var white = color.RGBA{255, 255, 255, 255}
+
+// newRgba returns a new black image with the given size.
+func newRgba(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// count returns the number of pixels with non-zero red.
+func count(img *image.RGBA) int {
+	n := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+var linetests = []struct {
+	x1, y1, x2, y2 int
+	pixels         [][2]int
+}{
+	{10, 10, 13, 11, [][2]int{{10, 10}, {11, 10}, {12, 11}, {13, 11}}},
+	{13, 11, 10, 10, [][2]int{{10, 10}, {11, 10}, {12, 11}, {13, 11}}},
+	{5, 5, 5, 8, [][2]int{{5, 5}, {5, 6}, {5, 7}, {5, 8}}},
+	{3, 9, 1, 3, [][2]int{{3, 9}, {3, 8}, {2, 7}, {2, 6}, {2, 5}, {1, 4}, {1, 3}}},
+	{7, 7, 7, 7, [][2]int{{7, 7}}},
+}
+
+func TestLine(t *testing.T) {
+	for _, test := range linetests {
+		img := newRgba(20, 20)
+		Line(img, test.x1, test.y1, test.x2, test.y2, white)
+		if n := count(img); n != len(test.pixels) {
+			t.Errorf("expected '%v' pixels but got '%v'", len(test.pixels), n)
+		}
+		for _, p := range test.pixels {
+			if c := img.At(p[0], p[1]); c != white {
+				t.Errorf("expected '%v' at '%v' but got '%v'", white, p, c)
+			}
+		}
+	}
+}
+
+func TestLineClip(t *testing.T) {
+	img := newRgba(5, 5)
+	Line(img, -10, 2, 10, 2, white)
+	if n := count(img); n != 5 {
+		t.Errorf("expected '%v' pixels but got '%v'", 5, n)
+	}
+}
+
+func TestLineAa(t *testing.T) {
+	img := newRgba(20, 20)
+	// Horizontal line exactly through pixel centers of row 5, the end
+	// pixels are only covered half
+	LineAa(img, 2.5, 5.5, 10.5, 5.5, white)
+	for x := 3; x <= 9; x++ {
+		if c := img.At(x, 5); c != white {
+			t.Errorf("expected '%v' at '%v' but got '%v'", white, x, c)
+		}
+	}
+	if r := img.RGBAAt(2, 5).R; r != 128 || img.RGBAAt(10, 5).R != 128 {
+		t.Errorf("expected half coverage but got '%v' and '%v'", r, img.RGBAAt(10, 5).R)
+	}
+	if n := count(img); n != 9 {
+		t.Errorf("expected '%v' pixels but got '%v'", 9, n)
+	}
+	// Horizontal line between two rows covers both half
+	img = newRgba(20, 20)
+	LineAa(img, 2.5, 6, 10.5, 6, white)
+	r := img.RGBAAt(5, 5).R
+	if r != img.RGBAAt(5, 6).R || r < 126 || r > 128 {
+		t.Errorf("expected half coverage but got '%v' and '%v'", r, img.RGBAAt(5, 6).R)
+	}
+}
@@ -0,0 +1,286 @@
+package draw
+
+import (
+	"image/color"
+	stddraw "image/draw"
+	"math"
+)
+
+// FillRule decides which points are inside a path with overlapping or nested
+// parts.
+type FillRule int
+
+const (
+	// NonZero fills points around which the path winds a non-zero number of
+	// times.
+	NonZero FillRule = iota
+
+	// EvenOdd fills points from which a ray crosses the path an odd number of
+	// times. Nested shapes give holes regardless of their direction.
+	EvenOdd
+)
+
+// defTolerance is the default maximum distance in pixels between a curve and
+// its flattened polyline.
+const defTolerance = 0.25
+
+// maxDepth limits the subdivision of curves.
+const maxDepth = 16
+
+// subpath is a connected sequence of points.
+type subpath struct {
+	pts    []Point
+	closed bool
+}
+
+// Path is a shape made of one or more subpaths of straight lines, curves and
+// arcs. Curves and arcs are flattened to straight lines when added, so they
+// can be filled and outlined the same way as polygons. Angles are in radians
+// and go from the positive x axis towards the positive y axis, which is
+// clockwise on screen.
+type Path struct {
+
+	// Tolerance is the maximum distance in pixels between a curve and the
+	// straight lines approximating it. If 0 it is 0.25.
+	Tolerance float64
+
+	subs []subpath
+}
+
+// NewPath returns a new empty path.
+func NewPath() *Path {
+	return &Path{}
+}
+
+// tol returns the flattening tolerance.
+func (p *Path) tol() float64 {
+	if p.Tolerance <= 0 {
+		return defTolerance
+	}
+	return p.Tolerance
+}
+
+// open returns the subpath to continue. After a closed subpath, a new one
+// starts at its first point. An empty path starts at (0,0).
+func (p *Path) open() *subpath {
+	if len(p.subs) == 0 {
+		p.MoveTo(0, 0)
+	} else if s := p.subs[len(p.subs)-1]; s.closed {
+		p.MoveTo(s.pts[0][0], s.pts[0][1])
+	}
+	return &p.subs[len(p.subs)-1]
+}
+
+// MoveTo starts a new subpath at (x,y).
+func (p *Path) MoveTo(x, y float64) {
+	p.subs = append(p.subs, subpath{pts: []Point{{x, y}}})
+}
+
+// LineTo adds a straight line from the current point to (x,y).
+func (p *Path) LineTo(x, y float64) {
+	s := p.open()
+	s.pts = append(s.pts, Point{x, y})
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to (x,y) with
+// control point (cx,cy).
+func (p *Path) QuadTo(cx, cy, x, y float64) {
+	s := p.open()
+	a := s.pts[len(s.pts)-1]
+	s.pts = flattenQuad(s.pts, a, Point{cx, cy}, Point{x, y}, p.tol(), 0)
+}
+
+// CubeTo adds a cubic Bézier curve from the current point to (x,y) with
+// control points (c1x,c1y) and (c2x,c2y).
+func (p *Path) CubeTo(c1x, c1y, c2x, c2y, x, y float64) {
+	s := p.open()
+	a := s.pts[len(s.pts)-1]
+	b := Point{c1x, c1y}
+	c := Point{c2x, c2y}
+	s.pts = flattenCube(s.pts, a, b, c, Point{x, y}, p.tol(), 0)
+}
+
+// Arc adds an elliptical arc around (cx,cy) with radii rx and ry from angle a0
+// to angle a1. If a1 < a0 the arc goes counter-clockwise. If there is an open
+// subpath a straight line connects its current point with the start of the
+// arc, otherwise the arc starts a new subpath.
+func (p *Path) Arc(cx, cy, rx, ry, a0, a1 float64) {
+	start := Point{cx + rx*math.Cos(a0), cy + ry*math.Sin(a0)}
+	if len(p.subs) == 0 || p.subs[len(p.subs)-1].closed {
+		p.MoveTo(start[0], start[1])
+	}
+	s := &p.subs[len(p.subs)-1]
+	if s.pts[len(s.pts)-1] != start {
+		s.pts = append(s.pts, start)
+	}
+	n := arcSteps(math.Max(math.Abs(rx), math.Abs(ry)), a1-a0, p.tol())
+	for i := 1; i <= n; i++ {
+		a := a0 + (a1-a0)*float64(i)/float64(n)
+		s.pts = append(s.pts, Point{cx + rx*math.Cos(a), cy + ry*math.Sin(a)})
+	}
+}
+
+// Close closes the current subpath with a straight line back to its first
+// point.
+func (p *Path) Close() {
+	if len(p.subs) > 0 {
+		p.subs[len(p.subs)-1].closed = true
+	}
+}
+
+// Polygon adds a closed subpath through the given points.
+func (p *Path) Polygon(pts ...Point) {
+	if len(pts) == 0 {
+		return
+	}
+	p.MoveTo(pts[0][0], pts[0][1])
+	for _, v := range pts[1:] {
+		p.LineTo(v[0], v[1])
+	}
+	p.Close()
+}
+
+// Rect adds a closed rectangle with top left corner (x,y), width w and height
+// h.
+func (p *Path) Rect(x, y, w, h float64) {
+	p.Polygon(Point{x, y}, Point{x + w, y}, Point{x + w, y + h}, Point{x, y + h})
+}
+
+// RoundRect adds a closed rectangle like Rect with the corners rounded by
+// quarter circles with radius r. The radius is limited to half of the shorter
+// side.
+func (p *Path) RoundRect(x, y, w, h, r float64) {
+	if w < 0 {
+		x, w = x+w, -w
+	}
+	if h < 0 {
+		y, h = y+h, -h
+	}
+	r = math.Min(r, math.Min(w, h)/2)
+	if r <= 0 {
+		p.Rect(x, y, w, h)
+		return
+	}
+	p.MoveTo(x+r, y)
+	p.Arc(x+w-r, y+r, r, r, -math.Pi/2, 0)
+	p.Arc(x+w-r, y+h-r, r, r, 0, math.Pi/2)
+	p.Arc(x+r, y+h-r, r, r, math.Pi/2, math.Pi)
+	p.Arc(x+r, y+r, r, r, math.Pi, 3*math.Pi/2)
+	p.Close()
+}
+
+// Ellipse adds a closed ellipse around (cx,cy) with radii rx and ry.
+func (p *Path) Ellipse(cx, cy, rx, ry float64) {
+	p.MoveTo(cx+rx, cy)
+	p.Arc(cx, cy, rx, ry, 0, 2*math.Pi)
+	p.Close()
+}
+
+// Circle adds a closed circle around (cx,cy) with radius r.
+func (p *Path) Circle(cx, cy, r float64) {
+	p.Ellipse(cx, cy, r, r)
+}
+
+// polys returns the points of all subpaths with at least 2 points, ready for
+// filling.
+func (p *Path) polys() [][]Point {
+	var polys [][]Point
+	for _, s := range p.subs {
+		if len(s.pts) > 1 {
+			polys = append(polys, s.pts)
+		}
+	}
+	return polys
+}
+
+// mid returns the point halfway between a and b.
+func mid(a, b Point) Point {
+	return Point{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2}
+}
+
+// segDist returns the distance of point p from the line segment between a and
+// b.
+func segDist(p, a, b Point) float64 {
+	dx := b[0] - a[0]
+	dy := b[1] - a[1]
+	l2 := dx*dx + dy*dy
+	t := 0.0
+	if l2 > 0 {
+		t = ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / l2
+		t = math.Max(0, math.Min(1, t))
+	}
+	return math.Hypot(p[0]-a[0]-t*dx, p[1]-a[1]-t*dy)
+}
+
+// flattenQuad appends points approximating the quadratic Bézier curve from a
+// to c with control point b, without a itself. The curve is split in half
+// (de Casteljau) until it is closer to its chord than tol. A quadratic curve
+// is at most half as far from its chord as its control point.
+func flattenQuad(pts []Point, a, b, c Point, tol float64, depth int) []Point {
+	if depth >= maxDepth || segDist(b, a, c)/2 <= tol {
+		return append(pts, c)
+	}
+	ab := mid(a, b)
+	bc := mid(b, c)
+	m := mid(ab, bc)
+	pts = flattenQuad(pts, a, ab, m, tol, depth+1)
+	return flattenQuad(pts, m, bc, c, tol, depth+1)
+}
+
+// flattenCube appends points approximating the cubic Bézier curve from a to d
+// with control points b and c, without a itself. Like flattenQuad, using that
+// a cubic curve is at most 3/4 as far from its chord as its farthest control
+// point.
+func flattenCube(pts []Point, a, b, c, d Point, tol float64, depth int) []Point {
+	dist := math.Max(segDist(b, a, d), segDist(c, a, d))
+	if depth >= maxDepth || dist*3/4 <= tol {
+		return append(pts, d)
+	}
+	ab := mid(a, b)
+	bc := mid(b, c)
+	cd := mid(c, d)
+	abc := mid(ab, bc)
+	bcd := mid(bc, cd)
+	m := mid(abc, bcd)
+	pts = flattenCube(pts, a, ab, abc, m, tol, depth+1)
+	return flattenCube(pts, m, bcd, cd, d, tol, depth+1)
+}
+
+// arcSteps returns the number of straight lines needed to approximate an arc
+// with radius r spanning angle a, so that no line is farther than tol from the
+// arc.
+func arcSteps(r, a, tol float64) int {
+	// A chord spanning angle t is r*(1-cos(t/2)) away from the arc
+	t := math.Pi
+	if tol < r {
+		t = 2 * math.Acos(1-tol/r)
+	}
+	n := int(math.Ceil(math.Abs(a) / t))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// Fill fills the inside of the path with color c. Open subpaths are closed
+// implicitly for filling. With aa the edges are anti-aliased, otherwise
+// pixels are filled if their center is inside.
+func Fill(dst stddraw.Image, p *Path, c color.Color, rule FillRule, aa bool) {
+	polys := p.polys()
+	if len(polys) > 0 {
+		fill(dst, polys, c, rule == EvenOdd, aa)
+	}
+}
+
+// Outline draws the lines of the path with the width, caps and joins of the
+// stroke. Closed subpaths get a join instead of caps where they start.
+// Overlapping parts are only drawn once.
+func Outline(dst stddraw.Image, p *Path, c color.Color, s *Stroke) {
+	var polys [][]Point
+	for _, sub := range p.subs {
+		polys = append(polys, strokePolys(sub.pts, sub.closed, s)...)
+	}
+	if len(polys) > 0 {
+		fill(dst, polys, c, false, s.Aa)
+	}
+}
@@ -0,0 +1,122 @@
+package draw
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlattenQuad(t *testing.T) {
+	a, b, c := Point{0, 0}, Point{50, 100}, Point{100, 0}
+	pts := flattenQuad([]Point{a}, a, b, c, 0.25, 0)
+	if pts[len(pts)-1] != c {
+		t.Errorf("expected '%v' but got '%v'", c, pts[len(pts)-1])
+	}
+	// Exact curve at the middle of every flattened line must be close
+	for i := 0; i < 64; i++ {
+		u := (float64(i) + 0.5) / 64
+		q := Point{
+			(1-u)*(1-u)*a[0] + 2*u*(1-u)*b[0] + u*u*c[0],
+			(1-u)*(1-u)*a[1] + 2*u*(1-u)*b[1] + u*u*c[1],
+		}
+		d := math.Inf(1)
+		for j := 1; j < len(pts); j++ {
+			d = math.Min(d, segDist(q, pts[j-1], pts[j]))
+		}
+		if d > 0.25 {
+			t.Errorf("expected distance <= 0.25 but got '%v'", d)
+		}
+	}
+	// A straight curve needs no subdivision
+	pts = flattenCube(nil, a, Point{30, 0}, Point{60, 0}, c, 0.25, 0)
+	if len(pts) != 1 {
+		t.Errorf("expected '%v' points but got '%v'", 1, len(pts))
+	}
+}
+
+var arcsteptests = []struct {
+	r, a, tol float64
+	steps     int
+}{
+	{10, 2 * math.Pi, 10, 2},
+	{100, 2 * math.Pi, 0.25, 45},
+	{100, -math.Pi / 2, 0.25, 12},
+	{1, 0, 0.25, 1},
+}
+
+func TestArcSteps(t *testing.T) {
+	for _, test := range arcsteptests {
+		if n := arcSteps(test.r, test.a, test.tol); n != test.steps {
+			t.Errorf("expected '%v' but got '%v'", test.steps, n)
+		}
+	}
+}
+
+func TestFillRule(t *testing.T) {
+	// Square with a nested square in the same direction
+	p := NewPath()
+	p.Rect(0, 0, 10, 10)
+	p.Rect(2, 2, 6, 6)
+	img := newRgba(10, 10)
+	Fill(img, p, white, NonZero, false)
+	if n := count(img); n != 100 {
+		t.Errorf("expected '%v' pixels but got '%v'", 100, n)
+	}
+	img = newRgba(10, 10)
+	Fill(img, p, white, EvenOdd, false)
+	if n := count(img); n != 64 {
+		t.Errorf("expected '%v' pixels but got '%v'", 64, n)
+	}
+}
+
+func TestCircle(t *testing.T) {
+	// Pixel centers inside the exact circle
+	should := 316
+	p := &Path{Tolerance: 0.001}
+	p.Circle(20, 20, 10)
+	img := newRgba(40, 40)
+	Fill(img, p, white, NonZero, false)
+	if n := count(img); n != should {
+		t.Errorf("expected '%v' pixels but got '%v'", should, n)
+	}
+	// The default tolerance loses at most a thin ring
+	p = NewPath()
+	p.Circle(20, 20, 10)
+	img = newRgba(40, 40)
+	Fill(img, p, white, NonZero, false)
+	if n := count(img); n > should || n < should-16 {
+		t.Errorf("expected about '%v' pixels but got '%v'", should, n)
+	}
+}
+
+func TestRoundRect(t *testing.T) {
+	p := NewPath()
+	p.RoundRect(0, 0, 20, 10, 4)
+	img := newRgba(20, 10)
+	Fill(img, p, white, NonZero, false)
+	// Corners are cut off, edges between them are straight
+	for _, test := range []struct {
+		x, y int
+		in   bool
+	}{
+		{0, 0, false},
+		{19, 9, false},
+		{4, 0, true},
+		{0, 5, true},
+		{10, 5, true},
+	} {
+		if in := img.RGBAAt(test.x, test.y).R > 0; in != test.in {
+			t.Errorf("expected '%v' at (%v,%v) but got '%v'", test.in, test.x, test.y, in)
+		}
+	}
+}
+
+func TestOutline(t *testing.T) {
+	p := NewPath()
+	p.Rect(5, 5, 10, 10)
+	img := newRgba(20, 20)
+	Outline(img, p, white, &Stroke{Width: 2})
+	// 12x12 square minus the inner 8x8
+	if n := count(img); n != 80 {
+		t.Errorf("expected '%v' pixels but got '%v'", 80, n)
+	}
+}
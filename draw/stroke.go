@@ -0,0 +1,240 @@
+package draw
+
+import (
+	"image/color"
+	stddraw "image/draw"
+	"math"
+)
+
+// Cap is the shape at the ends of a wide line.
+type Cap int
+
+const (
+	// CapButt ends the line exactly at its end points.
+	CapButt Cap = iota
+
+	// CapSquare extends the line by half its width beyond the end points.
+	CapSquare
+
+	// CapRound adds a half circle around the end points.
+	CapRound
+)
+
+// Join is the shape where two segments of a wide line meet.
+type Join int
+
+const (
+	// JoinMiter extends the outer edges of both segments until they meet. If
+	// the tip would get longer than the miter limit a bevel is used instead.
+	JoinMiter Join = iota
+
+	// JoinBevel connects the outer corners of both segments with a straight
+	// edge.
+	JoinBevel
+
+	// JoinRound adds a circle around the point where the segments meet.
+	JoinRound
+)
+
+// Stroke describes how lines are drawn.
+type Stroke struct {
+
+	// Width of the line in pixels.
+	Width float64
+
+	// Cap at both ends of the line.
+	Cap Cap
+
+	// Join between segments.
+	Join Join
+
+	// MiterLimit is the maximum ratio of the miter length to the line width
+	// for JoinMiter. If 0 the limit is 4.
+	MiterLimit float64
+
+	// Aa enables anti-aliased edges.
+	Aa bool
+}
+
+// NewStroke returns a new anti-aliased stroke with the given width, butt caps
+// and miter joins.
+func NewStroke(w float64) *Stroke {
+	return &Stroke{Width: w, Aa: true}
+}
+
+// area returns twice the signed area of a polygon.
+func area(p []Point) float64 {
+	a := 0.0
+	for i := range p {
+		q := p[(i+1)%len(p)]
+		a += p[i][0]*q[1] - q[0]*p[i][1]
+	}
+	return a
+}
+
+// orient returns the polygon with positive orientation, reversing it if
+// needed. Filling several polygons with the same orientation with the non-zero
+// rule gives their union.
+func orient(p []Point) []Point {
+	if area(p) >= 0 {
+		return p
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// circleSteps returns the number of segments needed to approximate a circle
+// with radius r closely enough for drawing.
+func circleSteps(r float64) int {
+	n := int(math.Ceil(2 * math.Pi * r / 2))
+	if n < 8 {
+		n = 8
+	}
+	return n
+}
+
+// circle returns a polygon approximating a circle around c with radius r.
+func circle(c Point, r float64) []Point {
+	n := circleSteps(r)
+	p := make([]Point, n)
+	for i := range p {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		p[i] = Point{c[0] + r*math.Cos(a), c[1] + r*math.Sin(a)}
+	}
+	return p
+}
+
+// strokePolys returns polygons that together cover a wide line through the
+// given points: a rectangle for every segment, plus joins and caps. If closed
+// the last point is connected to the first one and there are no caps.
+func strokePolys(pts []Point, closed bool, s *Stroke) [][]Point {
+	// Drop repeated points, they have no direction
+	var ps []Point
+	for i, p := range pts {
+		if i == 0 || p != pts[i-1] {
+			ps = append(ps, p)
+		}
+	}
+	if closed && len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+		ps = ps[:len(ps)-1]
+	}
+	hw := s.Width / 2
+	if len(ps) == 0 || hw <= 0 {
+		return nil
+	}
+	if len(ps) == 1 {
+		switch s.Cap {
+		case CapRound:
+			return [][]Point{circle(ps[0], hw)}
+		case CapSquare:
+			p := ps[0]
+			return [][]Point{{
+				{p[0] - hw, p[1] - hw},
+				{p[0] + hw, p[1] - hw},
+				{p[0] + hw, p[1] + hw},
+				{p[0] - hw, p[1] + hw},
+			}}
+		}
+		return nil
+	}
+	nseg := len(ps) - 1
+	if closed {
+		nseg = len(ps)
+	}
+	// Unit directions and normals of the segments
+	dirs := make([]Point, nseg)
+	norms := make([]Point, nseg)
+	for i := 0; i < nseg; i++ {
+		a := ps[i]
+		b := ps[(i+1)%len(ps)]
+		l := math.Hypot(b[0]-a[0], b[1]-a[1])
+		dirs[i] = Point{(b[0] - a[0]) / l, (b[1] - a[1]) / l}
+		norms[i] = Point{-dirs[i][1], dirs[i][0]}
+	}
+	var polys [][]Point
+	for i := 0; i < nseg; i++ {
+		a := ps[i]
+		b := ps[(i+1)%len(ps)]
+		d := dirs[i]
+		n := norms[i]
+		if !closed && s.Cap == CapSquare {
+			if i == 0 {
+				a = Point{a[0] - d[0]*hw, a[1] - d[1]*hw}
+			}
+			if i == nseg-1 {
+				b = Point{b[0] + d[0]*hw, b[1] + d[1]*hw}
+			}
+		}
+		polys = append(polys, orient([]Point{
+			{a[0] + n[0]*hw, a[1] + n[1]*hw},
+			{b[0] + n[0]*hw, b[1] + n[1]*hw},
+			{b[0] - n[0]*hw, b[1] - n[1]*hw},
+			{a[0] - n[0]*hw, a[1] - n[1]*hw},
+		}))
+	}
+	// Joins at the inner points, and at all points of closed lines
+	for i := 0; i < nseg; i++ {
+		if !closed && i == nseg-1 {
+			break
+		}
+		j := (i + 1) % nseg
+		p := ps[(i+1)%len(ps)]
+		polys = append(polys, join(p, dirs[i], norms[i], dirs[j], norms[j], hw, s)...)
+	}
+	if !closed && s.Cap == CapRound {
+		polys = append(polys, circle(ps[0], hw), circle(ps[len(ps)-1], hw))
+	}
+	return polys
+}
+
+// join returns the polygons for the join at point p between a segment with
+// direction d1 and normal n1 and the following one with d2 and n2.
+func join(p, d1, n1, d2, n2 Point, hw float64, s *Stroke) [][]Point {
+	if s.Join == JoinRound {
+		return [][]Point{circle(p, hw)}
+	}
+	// The outer side is the one where the offset edges meet ahead of the
+	// first segment's end
+	cross := d1[0]*d2[1] - d1[1]*d2[0]
+	if cross == 0 {
+		return nil
+	}
+	side := -1.0
+	if cross < 0 {
+		side = 1
+	}
+	a := Point{p[0] + side*n1[0]*hw, p[1] + side*n1[1]*hw}
+	b := Point{p[0] + side*n2[0]*hw, p[1] + side*n2[1]*hw}
+	bevel := [][]Point{orient([]Point{p, a, b})}
+	if s.Join == JoinBevel {
+		return bevel
+	}
+	// Miter tip where the lines a + t*d1 and b - u*d2 meet
+	t := ((b[0]-a[0])*d2[1] - (b[1]-a[1])*d2[0]) / cross
+	m := Point{a[0] + t*d1[0], a[1] + t*d1[1]}
+	limit := s.MiterLimit
+	if limit == 0 {
+		limit = 4
+	}
+	if math.Hypot(m[0]-p[0], m[1]-p[1]) > limit*hw {
+		return bevel
+	}
+	return [][]Point{orient([]Point{p, a, m, b})}
+}
+
+// WideLine draws a line between (x1,y1) and (x2,y2) with the width and caps of
+// the stroke.
+func WideLine(dst stddraw.Image, x1, y1, x2, y2 float64, c color.Color, s *Stroke) {
+	Polyline(dst, []Point{{x1, y1}, {x2, y2}}, c, s)
+}
+
+// Polyline draws connected line segments through the given points with the
+// width, caps and joins of the stroke. Overlapping parts are only drawn once.
+func Polyline(dst stddraw.Image, pts []Point, c color.Color, s *Stroke) {
+	polys := strokePolys(pts, false, s)
+	if len(polys) > 0 {
+		fill(dst, polys, c, false, s.Aa)
+	}
+}
@@ -0,0 +1,98 @@
+package draw
+
+import (
+	"testing"
+)
+
+var spantests = []struct {
+	x0, x1 float64
+	cov    []float64
+}{
+	{1, 3, []float64{0, 1, 1, 0}},
+	{0.5, 1.25, []float64{0.5, 0.25, 0, 0}},
+	{-2, 2.5, []float64{1, 1, 0.5, 0}},
+	{3.5, 9, []float64{0, 0, 0, 0.5}},
+}
+
+func TestAddSpan(t *testing.T) {
+	for _, test := range spantests {
+		cov := make([]float64, 4)
+		addSpan(cov, test.x0, test.x1, 1)
+		for i := range cov {
+			if cov[i] != test.cov[i] {
+				t.Errorf("expected '%v' but got '%v'", test.cov, cov)
+				break
+			}
+		}
+	}
+}
+
+func TestArea(t *testing.T) {
+	p := []Point{{0, 0}, {2, 0}, {2, 3}}
+	if a := area(p); a != 6 {
+		t.Errorf("expected '%v' but got '%v'", 6, a)
+	}
+	q := orient([]Point{{0, 0}, {2, 3}, {2, 0}})
+	if a := area(q); a != 6 {
+		t.Errorf("expected '%v' but got '%v'", 6, a)
+	}
+}
+
+var widetests = []struct {
+	stroke *Stroke
+	pixels int
+}{
+	// Butt: 10 long and 4 wide
+	{&Stroke{Width: 4, Cap: CapButt}, 40},
+	// Square: 2 more at each end
+	{&Stroke{Width: 4, Cap: CapSquare}, 56},
+	// Round: about a circle with radius 2 more
+	{&Stroke{Width: 4, Cap: CapRound}, 52},
+}
+
+func TestWideLine(t *testing.T) {
+	for _, test := range widetests {
+		img := newRgba(30, 30)
+		WideLine(img, 10, 10, 20, 10, white, test.stroke)
+		if n := count(img); n != test.pixels {
+			t.Errorf("expected '%v' pixels but got '%v'", test.pixels, n)
+		}
+	}
+}
+
+func TestWideLineAa(t *testing.T) {
+	img := newRgba(30, 30)
+	WideLine(img, 10, 10, 20, 10, white, &Stroke{Width: 3, Aa: true})
+	// Edges at y = 8.5 and 11.5 cover pixel rows 8 and 11 half
+	should := [4]uint8{128, 255, 255, 128}
+	is := [4]uint8{
+		img.RGBAAt(15, 8).R,
+		img.RGBAAt(15, 9).R,
+		img.RGBAAt(15, 10).R,
+		img.RGBAAt(15, 11).R,
+	}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+}
+
+var jointests = []struct {
+	join   Join
+	pixels int
+}{
+	// Both segments cover 76 pixels, joins fill the outer corner
+	{JoinBevel, 77},
+	{JoinMiter, 80},
+	{JoinRound, 79},
+}
+
+func TestPolylineJoin(t *testing.T) {
+	for _, test := range jointests {
+		img := newRgba(30, 30)
+		pts := []Point{{5, 5}, {15, 5}, {15, 15}}
+		Polyline(img, pts, white, &Stroke{Width: 4, Join: test.join})
+		if n := count(img); n != test.pixels {
+			t.Errorf("expected '%v' pixels but got '%v'", test.pixels, n)
+		}
+	}
+}
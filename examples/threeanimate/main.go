@@ -4,6 +4,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/amsibamsi/three/loop"
 	tmath "github.com/amsibamsi/three/math"
 	"github.com/amsibamsi/three/window"
 	"math/rand"
@@ -21,10 +22,13 @@ func main() {
 		os.Exit(-1)
 	}
 	defer window.Terminate()
+	// Hide the cursor and keep it in the window
+	w.SetCursorCaptured(true)
 	x := width / 2
 	y := height / 2
 	r := rand.New(rand.NewSource(0))
-	for close := false; !close; close = w.ShouldClose() {
+	l := loop.NewLoop(w)
+	l.Render = func(alpha float64) {
 		w.Setxy(x, y, 0, 0, 0)
 		width = w.Width()
 		height = w.Height()
@@ -33,7 +37,7 @@ func main() {
 		y = y + r.Intn(3) - 1
 		y = tmath.Mini(height, tmath.Maxi(0, y))
 		w.Setxy(x, y, 255, 0, 0)
-		w.Update()
 	}
+	l.Run()
 	w.Destroy()
 }
@@ -0,0 +1,123 @@
+// Package main contains an example program that rasterizes a rotating cube on
+// worker goroutines while the main thread only presents finished frames.
+package main
+
+import (
+	"fmt"
+	"github.com/amsibamsi/three/image"
+	"github.com/amsibamsi/three/loop"
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/text"
+	"github.com/amsibamsi/three/window"
+	stdimage "image"
+	"image/color"
+	"time"
+)
+
+// frame is a framebuffer to render into and the window content resolved from
+// it.
+type frame struct {
+	fb   *render.Framebuffer
+	bgra []byte
+}
+
+// bgra is window content stored like by image.FloatImage.ResolveBgra as image
+// to draw text on.
+type bgra struct {
+	pix  []byte
+	w, h int
+}
+
+// ColorModel returns the RGBA color model.
+func (b *bgra) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds returns the rectangle from (0,0) to the width and height.
+func (b *bgra) Bounds() stdimage.Rectangle {
+	return stdimage.Rect(0, 0, b.w, b.h)
+}
+
+// At returns the opaque color of pixel (x,y).
+func (b *bgra) At(x, y int) color.Color {
+	p := b.pix[4*(y*b.w+x):]
+	return color.RGBA{p[2], p[1], p[0], 255}
+}
+
+// Set sets the color of pixel (x,y), translucent colors as if drawn on black.
+func (b *bgra) Set(x, y int, c color.Color) {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return
+	}
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	p := b.pix[4*(y*b.w+x):]
+	p[0], p[1], p[2], p[3] = rgba.B, rgba.G, rgba.R, 255
+}
+
+// main creates a scene with a lit cube and a pipeline with 3 frames. Every
+// frame the main thread submits the current time to render if a frame is free,
+// and shows the newest rendered frame. The render worker rasterizes, tone maps
+// and draws the text, so the main thread only copies the result to the
+// window. Only the render worker touches the scene.
+func main() {
+	win, err := window.NewWindow(1024, 768, "Three Async", true)
+	if err != nil {
+		panic(err)
+	}
+	defer window.Terminate()
+	cam := render.NewDefCam()
+	cube := render.NewObject(render.NewCube(), render.NewMaterial(0.9, 0.5, 0.2))
+	scene := render.NewScene()
+	scene.Add(cube)
+	scene.AddLight(&render.Light{
+		Pos:   geom.Vec3{2, 2, 0},
+		Color: geom.Vec3{20, 20, 20},
+	})
+	scene.Ambient = geom.Vec3{0.05, 0.05, 0.05}
+	bufs := make([]interface{}, 3)
+	for i := range bufs {
+		bufs[i] = &frame{}
+	}
+	p, err := loop.NewPipeline(bufs, 1)
+	if err != nil {
+		panic(err)
+	}
+	defer p.Close()
+	face := text.NewBitmapFace(2)
+	var t float64
+	l := loop.NewLoop(win)
+	l.Close = func() bool {
+		return win.KeyDown(window.KeyQ)
+	}
+	l.Update = func(dt time.Duration) {
+		t += dt.Seconds()
+	}
+	l.Render = func(alpha float64) {
+		// Copies of the state for the render worker
+		ft := t
+		w, h := win.Width(), win.Height()
+		s := fmt.Sprintf("%.0f fps, %d dropped", l.Stats.Fps(), p.Dropped())
+		p.TrySubmit(func(buf interface{}) interface{} {
+			f := buf.(*frame)
+			if f.fb == nil || f.fb.Width() != w || f.fb.Height() != h {
+				f.fb = render.NewFramebuffer(w, h)
+				f.bgra = make([]byte, 4*w*h)
+			}
+			cam.Ar = float64(w) / float64(h)
+			m := render.TranslTransf(&geom.Vec3{0, 0, -3})
+			m.Mul(render.RotTransf(&geom.Vec3{1, 1, 0}, ft))
+			cube.Transf = *m
+			f.fb.Clear(&geom.Vec3{0, 0, 0})
+			render.Rasterize(scene, cam, f.fb)
+			f.fb.Color.ResolveBgra(f.bgra, w, h, image.Aces)
+			text.Draw(&bgra{f.bgra, w, h}, face, s, 10, 10, color.White, text.Left)
+			return f
+		})
+		p.Present(func(buf interface{}) {
+			f := buf.(*frame)
+			win.DrawBgra(f.bgra, f.fb.Width(), f.fb.Height())
+		})
+	}
+	l.Run()
+}
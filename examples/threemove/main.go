@@ -1,53 +1,69 @@
 // Package main contains an example program that renders a simple triangle and
-// updates the camera according to player movement.
+// flies the camera around with keyboard and mouse.
 package main
 
 import (
+	"flag"
+	"fmt"
+	"github.com/amsibamsi/three/control"
 	"github.com/amsibamsi/three/geom"
-	mgeom "github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/loop"
 	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/text"
 	"github.com/amsibamsi/three/window"
+	"image/color"
 	"time"
 )
 
 // main creates a new scene with a camera and a triangle, renders the scene,
-// draws the result to a window and displays it. The middle point of the
-// triangle continuously changes position relative to the current time.
+// draws the result to a window and displays it. The camera moves with W, A, S,
+// D, Space and left shift, rolls with Q and E and turns with the mouse. The
+// frame rate and camera position are shown in the top left corner. F12 stores
+// a screenshot, escape quits. Optionally the window covers the primary
+// monitor.
 func main() {
-	win, err := window.NewWindow(1024, 768, "Three Move", true)
+	var full = flag.Bool("fullscreen", false, "Cover the primary monitor")
+	flag.Parse()
+	o := window.DefOptions()
+	o.Title = "Three Move"
+	o.CaptureCursor = true
+	if *full {
+		m, err := window.PrimaryMonitor()
+		if err != nil {
+			panic(err)
+		}
+		o.Monitor = m
+		o.Borderless = true
+	}
+	win, err := window.NewWindowOptions(o)
 	if err != nil {
 		panic(err)
 	}
 	defer window.Terminate()
 	cam := render.NewDefCam()
 	p := geom.NewTri4(-1, 0, -3, 0, 1, -3, 1, 0, -3)
-	then := time.Now()
-	now := time.Now()
-	for close := false; !close; close = win.ShouldClose() || win.KeyDown(window.KeyQ) {
-		then = now
-		now = time.Now()
-		dt := now.Sub(then)
-		d := mgeom.Vec3{0, 0, 0}
-		if win.KeyDown(window.KeyW) {
-			d.Add(&cam.At)
-		}
-		if win.KeyDown(window.KeyS) {
-			d.Sub(&cam.At)
-		}
-		if win.KeyDown(window.KeyA) {
-			d.Add(mgeom.Cross(&cam.At, &cam.Up))
-		}
-		if win.KeyDown(window.KeyD) {
-			d.Add(mgeom.Cross(&cam.Up, &cam.At))
-		}
-		d.Norm()
-		d.Scale(dt.Seconds())
-		cam.Eye.Add(&d)
+	shots := window.NewCapturer("threemove%04d.png")
+	face := text.NewBitmapFace(2)
+	l := loop.NewLoop(win)
+	ctl := control.NewFly(cam)
+	l.Close = func() bool {
+		return win.KeyDown(window.KeyEscape)
+	}
+	l.Update = func(dt time.Duration) {
+		ctl.Update(win, dt)
+	}
+	l.Render = func(alpha float64) {
 		cam.Ar = float64(win.Width()) / float64(win.Height())
 		t := cam.PerspTransf(win.Width(), win.Height())
 		q := p.Transf(t)
 		win.Clear()
 		q.Draw(win)
-		win.Update()
+		info := fmt.Sprintf("%.1f fps\n%.2f %.2f %.2f",
+			l.Stats.Fps(), cam.Eye[0], cam.Eye[1], cam.Eye[2])
+		text.Draw(win.Canvas(), face, info, 10, 10, color.White, text.Left)
+		if _, err := shots.Poll(win); err != nil {
+			panic(err)
+		}
 	}
+	l.Run()
 }
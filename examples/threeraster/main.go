@@ -0,0 +1,178 @@
+// Package main contains an example program that rasterizes or ray traces a
+// lit, rotating cube behind a translucent pane with high dynamic range and
+// displays it with tone mapping.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/amsibamsi/three/image"
+	"github.com/amsibamsi/three/loop"
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/text"
+	"github.com/amsibamsi/three/window"
+	"image/color"
+	"math"
+	"time"
+)
+
+// main creates a scene with a cube, a glass pane moving in front of it and a
+// bright light, renders it every frame into a framebuffer and resolves it
+// to the window. Keys 1, 2 and 3 select clamping, Reinhard and ACES tone
+// mapping. Keys 4 to 9 toggle debug drawing of axes, grid, face normals,
+// vertex normals, bounding boxes and lights. Clicking shows which object and
+// triangle is under the cursor and outlines the object. Key 0 switches between
+// rasterizing and ray tracing. Optionally choose anti-aliasing, render at a
+// lower resolution, and show an overview of the scene with the camera's
+// frustum in a second window.
+func main() {
+	var aaName = flag.String("aa", "none", "Anti-aliasing: none, ogss, rgss or msaa")
+	var overview = flag.Bool("overview", false, "Show an overview in a second window")
+	var scale = flag.Float64("scale", 1, "Resolution relative to the screen, e.g. 0.25 for big pixels")
+	flag.Parse()
+	var aa *render.Aa
+	switch *aaName {
+	case "ogss":
+		aa = render.NewSsaa(render.OrderedGrid(2))
+	case "rgss":
+		aa = render.NewSsaa(render.RotatedGrid())
+	case "msaa":
+		aa = render.NewMsaa(render.RotatedGrid())
+	}
+	win, err := window.NewWindow(1024, 768, "Three Raster", true)
+	if err != nil {
+		panic(err)
+	}
+	defer window.Terminate()
+	if err := win.SetResolutionScale(*scale); err != nil {
+		panic(err)
+	}
+	var over *window.Window
+	if *overview {
+		over, err = window.NewWindow(512, 384, "Three Raster Overview", true)
+		if err != nil {
+			panic(err)
+		}
+	}
+	cam := render.NewDefCam()
+	overCam := render.NewDefCam()
+	overCam.Eye = geom.Vec3{4, 3, 1}
+	overCam.At = geom.Vec3{0, 0, -2}
+	overDbg := render.NewDebug()
+	overDbg.Lights = true
+	overDbg.Frustums = []*render.Camera{cam}
+	var ofb *render.Framebuffer
+	cube := render.NewObject(render.NewCube(), render.NewMaterial(0.9, 0.5, 0.2))
+	glass := render.NewObject(render.NewCube(), &render.Material{
+		Color:        geom.Vec3{0.2, 0.4, 0.9},
+		Transparency: 0.6,
+	})
+	scene := render.NewScene()
+	scene.Add(cube, glass)
+	scene.AddLight(&render.Light{
+		Pos:   geom.Vec3{2, 2, 0},
+		Color: geom.Vec3{20, 20, 20},
+	})
+	scene.Ambient = geom.Vec3{0.05, 0.05, 0.05}
+	dbg := render.NewDebug()
+	dbg.Axes = false
+	dbg.Grid = false
+	tracer := render.NewRayTracer()
+	trace := false
+	// Key 0 toggles ray tracing, keys 4 to 9 parts of the debug drawing when
+	// pressed
+	toggles := map[window.Key]*bool{
+		window.Key0: &trace,
+		window.Key4: &dbg.Axes,
+		window.Key5: &dbg.Grid,
+		window.Key6: &dbg.FaceNormals,
+		window.Key7: &dbg.VertNormals,
+		window.Key8: &dbg.Bounds,
+		window.Key9: &dbg.Lights,
+	}
+	down := make(map[window.Key]bool)
+	names := map[*render.Object]string{cube: "cube", glass: "glass"}
+	face := text.NewBitmapFace(2)
+	clicked := false
+	picked := ""
+	var selected *render.Object
+	tm := image.ToneMap(image.Aces)
+	fb := render.NewFramebufferAa(win.Width(), win.Height(), aa)
+	fb.SetIds(true)
+	var t float64
+	l := loop.NewLoop(win)
+	l.Close = func() bool {
+		return win.KeyDown(window.KeyQ)
+	}
+	l.Update = func(dt time.Duration) {
+		t += dt.Seconds()
+		switch {
+		case win.KeyDown(window.Key1):
+			tm = image.Clamp
+		case win.KeyDown(window.Key2):
+			tm = image.Reinhard
+		case win.KeyDown(window.Key3):
+			tm = image.Aces
+		}
+		for k, on := range toggles {
+			d := win.KeyDown(k)
+			if d && !down[k] {
+				*on = !*on
+			}
+			down[k] = d
+		}
+		c := win.MouseDown(window.MouseLeft)
+		if c && !clicked {
+			x, y := win.CursorPos()
+			picked = "nothing"
+			selected = nil
+			if h := scene.Pick(cam.Ray(x, y, win.Width(), win.Height())); h != nil {
+				picked = fmt.Sprintf("%s, triangle %d", names[h.Object], h.Tri)
+				selected = h.Object
+			}
+		}
+		clicked = c
+	}
+	l.Render = func(alpha float64) {
+		if fb.Width() != win.Width() || fb.Height() != win.Height() {
+			fb = render.NewFramebufferAa(win.Width(), win.Height(), aa)
+			fb.SetIds(true)
+		}
+		cam.Ar = float64(win.Width()) / float64(win.Height())
+		m := render.TranslTransf(&geom.Vec3{0, 0, -3})
+		m.Mul(render.RotTransf(&geom.Vec3{1, 1, 0}, t))
+		cube.Transf = *m
+		m = render.TranslTransf(&geom.Vec3{math.Sin(t), 0, -1.8})
+		m.Mul(render.ScaleTransf(&geom.Vec3{0.6, 1, 0.05}))
+		glass.Transf = *m
+		fb.Clear(&geom.Vec3{0, 0, 0})
+		if trace {
+			tracer.Render(scene, cam, fb)
+		} else {
+			render.Rasterize(scene, cam, fb)
+		}
+		win.Resolve(fb.Color, tm)
+		dbg.Draw(win.Canvas(), scene, cam)
+		fb.Outline(win.Canvas(), scene, selected, color.RGBA{255, 255, 0, 255})
+		text.Draw(win.Canvas(), face, picked, 10, 10, color.White, text.Left)
+		if over == nil {
+			return
+		}
+		if over.ShouldClose() {
+			over.Destroy()
+			over = nil
+			return
+		}
+		overCam.Ar = float64(over.Width()) / float64(over.Height())
+		if ofb == nil || ofb.Width() != over.Width() || ofb.Height() != over.Height() {
+			ofb = render.NewFramebuffer(over.Width(), over.Height())
+		}
+		ofb.Clear(&geom.Vec3{0, 0, 0})
+		render.Rasterize(scene, overCam, ofb)
+		over.Resolve(ofb.Color, tm)
+		overDbg.Draw(over.Canvas(), scene, overCam)
+		over.Update()
+	}
+	l.Run()
+}
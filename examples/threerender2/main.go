@@ -3,7 +3,10 @@
 package main
 
 import (
+	"flag"
 	"github.com/amsibamsi/three/geom"
+	"github.com/amsibamsi/three/loop"
+	"github.com/amsibamsi/three/record"
 	"github.com/amsibamsi/three/render"
 	"github.com/amsibamsi/three/window"
 	"math"
@@ -13,25 +16,59 @@ import (
 // main creates a new scene with a camera and a triangle, renders the scene,
 // draws the result to a window and displays it. The middle point of the
 // triangle continuously changes position relative to the current time.
+// Optionally records a number of frames to a file, in which case the time
+// advances by exactly one frame per frame.
 func main() {
+	var filename = flag.String("record", "", "File to record to (.y4m, .gif or .png)")
+	var frames = flag.Int("frames", 100, "Number of frames to record")
+	var fps = flag.Int("fps", 30, "Frames per second to record")
+	flag.Parse()
 	win, err := window.NewWindow(1024, 768, "Three Render 2", true)
 	if err != nil {
 		panic(err)
 	}
 	defer window.Terminate()
+	// Hide the cursor and keep it in the window
+	win.SetCursorCaptured(true)
+	var rec record.Recorder
+	if *filename != "" {
+		rec, err = record.Create(*filename, *fps)
+		if err != nil {
+			panic(err)
+		}
+	}
 	cam := render.NewDefCam()
 	p := geom.NewTri4(-1, 0, -3, 0, 1, -3, 1, 0, -3)
-	for close := false; !close; close = win.ShouldClose() {
-		now := time.Now()
+	frame := 0
+	l := loop.NewLoop(win)
+	l.Close = func() bool {
+		return rec != nil && frame >= *frames
+	}
+	l.Render = func(alpha float64) {
+		now := float64(time.Now().UnixNano()) / 1e9
+		if rec != nil {
+			now = float64(frame) / float64(*fps)
+		}
 		m := &p[1][1]
-		*m = math.Sin(float64(now.UnixNano()) / 1e9)
+		*m = math.Sin(now)
 		c := &cam.At[0]
-		*c = math.Cos(float64(now.UnixNano()) / 1e9)
+		*c = math.Cos(now)
 		cam.Ar = float64(win.Width()) / float64(win.Height())
 		t := cam.PerspTransf(win.Width(), win.Height())
 		q := p.Transf(t)
 		win.Clear()
 		q.Draw(win)
-		win.Update()
+		if rec != nil {
+			if err := record.Capture(rec, win); err != nil {
+				panic(err)
+			}
+		}
+		frame++
+	}
+	l.Run()
+	if rec != nil {
+		if err := rec.Close(); err != nil {
+			panic(err)
+		}
 	}
 }
@@ -0,0 +1,109 @@
+// Package main contains an example program that path traces a box with a
+// metal and a glass cube lit by an area light and shows the image while it
+// converges.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/amsibamsi/three/image"
+	"github.com/amsibamsi/three/loop"
+	"github.com/amsibamsi/three/math/geom"
+	"github.com/amsibamsi/three/render"
+	"github.com/amsibamsi/three/text"
+	"github.com/amsibamsi/three/window"
+	"image/color"
+	"math"
+	"os"
+)
+
+// wall returns a square of size 1 in the xz plane centered at the origin.
+func wall() *render.Mesh {
+	return &render.Mesh{
+		Verts: []geom.Vec3{{-0.5, 0, -0.5}, {0.5, 0, -0.5}, {0.5, 0, 0.5}, {-0.5, 0, 0.5}},
+		Tris:  [][3]int{{0, 2, 1}, {0, 3, 2}},
+	}
+}
+
+// place returns a new object with mesh m and material mat translated by t,
+// rotated around axis by a and scaled by s.
+func place(m *render.Mesh, mat *render.Material, t, axis *geom.Vec3, a float64, s *geom.Vec3) *render.Object {
+	o := render.NewObject(m, mat)
+	tr := render.TranslTransf(t)
+	tr.Mul(render.RotTransf(axis, a))
+	tr.Mul(render.ScaleTransf(s))
+	o.Transf = *tr
+	return o
+}
+
+// scene returns a box open to the camera with colored side walls, a light in
+// the ceiling, a metal and a glass cube.
+func scene() *render.Scene {
+	s := render.NewScene()
+	w := wall()
+	x := &geom.Vec3{1, 0, 0}
+	z := &geom.Vec3{0, 0, 1}
+	size := &geom.Vec3{4, 1, 4}
+	white := render.NewMaterial(0.75, 0.75, 0.75)
+	s.Add(
+		place(w, white, &geom.Vec3{0, -2, -4}, x, 0, size),
+		place(w, white, &geom.Vec3{0, 2, -4}, x, 0, size),
+		place(w, white, &geom.Vec3{0, 0, -6}, x, math.Pi/2, size),
+		place(w, render.NewMaterial(0.75, 0.2, 0.2), &geom.Vec3{-2, 0, -4}, z, math.Pi/2, size),
+		place(w, render.NewMaterial(0.2, 0.75, 0.2), &geom.Vec3{2, 0, -4}, z, math.Pi/2, size),
+		place(w, &render.Material{Emission: geom.Vec3{12, 12, 12}},
+			&geom.Vec3{0, 1.99, -4}, x, 0, &geom.Vec3{1, 1, 1}),
+		place(render.NewCube(), render.NewMetal(0.9, 0.8, 0.6, 0.05),
+			&geom.Vec3{-0.8, -1.4, -4.6}, &geom.Vec3{0, 1, 0}, 0.4, &geom.Vec3{1.2, 1.2, 1.2}),
+		place(render.NewCube(), render.NewDielectric(1.5),
+			&geom.Vec3{0.8, -1.5, -3.4}, &geom.Vec3{0, 1, 0}, -0.3, &geom.Vec3{1, 1, 1}),
+	)
+	return s
+}
+
+// main path traces the scene and shows the image with the number of samples
+// so far, taking one sample per pixel each frame. Q quits. Optionally stops
+// after a number of samples and writes the image to a PNG file.
+func main() {
+	var out = flag.String("out", "", "PNG file to write the image to when done")
+	var samples = flag.Int("samples", 0, "Number of samples per pixel, 0 for no limit")
+	flag.Parse()
+	win, err := window.NewWindow(640, 480, "Three Trace", true)
+	if err != nil {
+		panic(err)
+	}
+	defer window.Terminate()
+	s := scene()
+	cam := render.NewDefCam()
+	cam.Fov = 0.8
+	face := text.NewBitmapFace(2)
+	var p *render.PathTracer
+	done := func() bool {
+		return *samples > 0 && p != nil && p.Samples() >= *samples
+	}
+	l := loop.NewLoop(win)
+	l.Close = func() bool {
+		return win.KeyDown(window.KeyQ) || done()
+	}
+	l.Render = func(alpha float64) {
+		if p == nil || p.Result().Width != win.Width() || p.Result().Height != win.Height() {
+			cam.Ar = float64(win.Width()) / float64(win.Height())
+			p = render.NewPathTracer(s, cam, win.Width(), win.Height())
+		}
+		p.Sample()
+		win.Resolve(p.Result(), image.Aces)
+		info := fmt.Sprintf("%d samples", p.Samples())
+		text.Draw(win.Canvas(), face, info, 10, 10, color.White, text.Left)
+	}
+	l.Run()
+	if *out != "" && p != nil {
+		f, err := os.Create(*out)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		if err := p.Image(image.Aces).WritePng(f); err != nil {
+			panic(err)
+		}
+	}
+}
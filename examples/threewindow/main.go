@@ -20,6 +20,8 @@ func run(testing bool) {
 		os.Exit(-1)
 	}
 	defer window.Terminate()
+	// Hide the cursor and keep it in the window
+	w.SetCursorCaptured(true)
 	for close := false; !close; close = w.ShouldClose() {
 		w.Update()
 		if testing {
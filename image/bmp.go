@@ -0,0 +1,132 @@
+package image
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// WriteBmp stores the image in uncompressed 24 bit BMP format to the given
+// writer. Alpha is dropped, so translucent pixels appear as if drawn on black.
+func (img *Image) WriteBmp(w io.Writer) error {
+	r := &img.Rgba
+	b := r.Bounds()
+	stride := (3*b.Dx() + 3) &^ 3
+	size := stride * b.Dy()
+	head := make([]byte, 54)
+	le := binary.LittleEndian
+	// File header
+	copy(head, "BM")
+	le.PutUint32(head[2:], uint32(54+size))
+	le.PutUint32(head[10:], 54)
+	// Info header
+	le.PutUint32(head[14:], 40)
+	le.PutUint32(head[18:], uint32(b.Dx()))
+	le.PutUint32(head[22:], uint32(b.Dy()))
+	le.PutUint16(head[26:], 1)
+	le.PutUint16(head[28:], 24)
+	le.PutUint32(head[34:], uint32(size))
+	le.PutUint32(head[38:], 2835) // 72 DPI
+	le.PutUint32(head[42:], 2835)
+	bw := bufio.NewWriter(w)
+	bw.Write(head)
+	row := make([]byte, stride)
+	// Rows are stored from bottom to top
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := r.PixOffset(x, y)
+			i := 3 * (x - b.Min.X)
+			row[i] = r.Pix[o+2]
+			row[i+1] = r.Pix[o+1]
+			row[i+2] = r.Pix[o]
+		}
+		bw.Write(row)
+	}
+	return bw.Flush()
+}
+
+// ReadBmp reads an image in uncompressed BMP format with 24 or 32 bits per
+// pixel. With 32 bits the fourth byte of each pixel is only taken as alpha if
+// the header declares an alpha mask, otherwise the image is opaque.
+func ReadBmp(r io.Reader) (*Image, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, 26)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, err
+	}
+	if string(head[:2]) != "BM" {
+		return nil, errors.New("Not a BMP image")
+	}
+	le := binary.LittleEndian
+	offset := int(le.Uint32(head[10:]))
+	infoSize := int(le.Uint32(head[14:]))
+	if infoSize < 40 || infoSize > 124 {
+		return nil, errors.New("Unsupported BMP header")
+	}
+	w := int(int32(le.Uint32(head[18:])))
+	h := int(int32(le.Uint32(head[22:])))
+	topDown := h < 0
+	if topDown {
+		h = -h
+	}
+	if w <= 0 || h <= 0 || !validSize(w, h) {
+		return nil, errors.New("Invalid BMP size")
+	}
+	info := make([]byte, infoSize-12)
+	if _, err := io.ReadFull(br, info); err != nil {
+		return nil, err
+	}
+	bpp := int(le.Uint16(info[2:]))
+	compr := le.Uint32(info[4:])
+	if bpp != 24 && bpp != 32 || compr != 0 && !(compr == 3 && bpp == 32) {
+		return nil, errors.New("Unsupported BMP format")
+	}
+	alpha := false
+	if compr == 3 {
+		// Bit fields must be the usual BGRA byte order
+		if infoSize < 52 ||
+			le.Uint32(info[28:]) != 0xff0000 ||
+			le.Uint32(info[32:]) != 0xff00 ||
+			le.Uint32(info[36:]) != 0xff {
+			return nil, errors.New("Unsupported BMP bit fields")
+		}
+		alpha = infoSize >= 56 && le.Uint32(info[40:]) == 0xff000000
+	}
+	if offset < 14+infoSize {
+		return nil, errors.New("Invalid BMP pixel offset")
+	}
+	if _, err := br.Discard(offset - 14 - infoSize); err != nil {
+		return nil, err
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	bytes := bpp / 8
+	row := make([]byte, (bytes*w+3)&^3)
+	for i := 0; i < h; i++ {
+		if _, err := io.ReadFull(br, row); err != nil {
+			return nil, err
+		}
+		y := h - 1 - i
+		if topDown {
+			y = i
+		}
+		for x := 0; x < w; x++ {
+			p := row[x*bytes:]
+			o := rgba.PixOffset(x, y)
+			rgba.Pix[o] = p[2]
+			rgba.Pix[o+1] = p[1]
+			rgba.Pix[o+2] = p[0]
+			rgba.Pix[o+3] = 255
+			if alpha {
+				// Premultiply since the stored colors are not
+				a := uint32(p[3])
+				rgba.Pix[o] = uint8(uint32(p[2]) * a / 255)
+				rgba.Pix[o+1] = uint8(uint32(p[1]) * a / 255)
+				rgba.Pix[o+2] = uint8(uint32(p[0]) * a / 255)
+				rgba.Pix[o+3] = p[3]
+			}
+		}
+	}
+	return &Image{*rgba}, nil
+}
@@ -0,0 +1,55 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+)
+
+func TestBmp(t *testing.T) {
+	// Width 5 needs row padding
+	img1 := NewImage(5, 2)
+	col := color.RGBA{10, 20, 30, 255}
+	img1.Rgba.Set(4, 0, col)
+	var buf bytes.Buffer
+	img1.WriteBmp(&buf)
+	if buf.Len() != 54+2*16 {
+		t.Errorf("expected '%v' bytes but got '%v'", 54+2*16, buf.Len())
+	}
+	img2, err := ReadBmp(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	should := [2]color.Color{col, color.RGBA{0, 0, 0, 255}}
+	is := [2]color.Color{img2.Rgba.At(4, 0), img2.Rgba.At(4, 1)}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+}
+
+func TestReadBmpInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	NewImage(1, 1).WriteBmp(&buf)
+	valid := buf.Bytes()
+	le := binary.LittleEndian
+	tests := []struct {
+		off int
+		v   uint32
+	}{
+		{18, 0},          // Zero width
+		{18, 0xffffffff}, // Negative width
+		{22, 0},          // Zero height
+		{22, 0x80000000}, // Minimum height
+		{18, 0x7fffffff}, // Huge width
+		{14, 0xffffffff}, // Huge info header
+		{10, 0},          // Pixels inside the header
+	}
+	for _, test := range tests {
+		b := append([]byte(nil), valid...)
+		le.PutUint32(b[test.off:], test.v)
+		if _, err := ReadBmp(bytes.NewReader(b)); err == nil {
+			t.Errorf("expected error for '%x' at '%v' but got none", test.v, test.off)
+		}
+	}
+}
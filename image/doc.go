@@ -1,2 +1,3 @@
-// Package image provides basic image drawing functions.
+// Package image provides basic image drawing functions and reading and writing
+// of several image file formats.
 package image
@@ -0,0 +1,55 @@
+package image
+
+// FloatImage is an image with float32 components for red, green, blue and
+// alpha. Colors are meant to be linear and are not limited to [0,1], which
+// allows to keep high dynamic range until the image is finally converted for
+// display.
+type FloatImage struct {
+
+	// Pix holds 4 consecutive components per pixel. Pixels go from left to
+	// right and then from top to bottom.
+	Pix []float32
+
+	// Width of the image
+	Width int
+
+	// Height of the image
+	Height int
+}
+
+// NewFloatImage returns a new float image with the given width and height and
+// opaque black background.
+func NewFloatImage(w, h int) *FloatImage {
+	f := &FloatImage{make([]float32, 4*w*h), w, h}
+	for i := 3; i < len(f.Pix); i += 4 {
+		f.Pix[i] = 1
+	}
+	return f
+}
+
+// At returns the color at (x,y). Outside of the image it returns transparent
+// black.
+func (f *FloatImage) At(x, y int) [4]float32 {
+	if x < 0 || x >= f.Width || y < 0 || y >= f.Height {
+		return [4]float32{}
+	}
+	i := 4 * (y*f.Width + x)
+	return [4]float32{f.Pix[i], f.Pix[i+1], f.Pix[i+2], f.Pix[i+3]}
+}
+
+// Set sets the color at (x,y). If (x,y) lies not within the image nothing is
+// set.
+func (f *FloatImage) Set(x, y int, c [4]float32) {
+	if x < 0 || x >= f.Width || y < 0 || y >= f.Height {
+		return
+	}
+	i := 4 * (y*f.Width + x)
+	copy(f.Pix[i:i+4], c[:])
+}
+
+// Clear sets all pixels to the given color.
+func (f *FloatImage) Clear(c [4]float32) {
+	for i := 0; i < len(f.Pix); i += 4 {
+		copy(f.Pix[i:i+4], c[:])
+	}
+}
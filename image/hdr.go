@@ -0,0 +1,246 @@
+package image
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WritePfm stores the image in portable float map format (PFM) with 3 channels
+// to the given writer. Alpha is dropped. Values are stored as little endian
+// float32 without any loss.
+func (f *FloatImage) WritePfm(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", f.Width, f.Height)
+	var b [12]byte
+	le := binary.LittleEndian
+	// Rows are stored from bottom to top
+	for y := f.Height - 1; y >= 0; y-- {
+		for x := 0; x < f.Width; x++ {
+			i := 4 * (y*f.Width + x)
+			le.PutUint32(b[0:], math.Float32bits(f.Pix[i]))
+			le.PutUint32(b[4:], math.Float32bits(f.Pix[i+1]))
+			le.PutUint32(b[8:], math.Float32bits(f.Pix[i+2]))
+			bw.Write(b[:])
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadPfm reads an image in portable float map format (PFM), either with 3
+// channels (PF) or grayscale (Pf), in any byte order. Alpha is set to 1.
+func ReadPfm(r io.Reader) (*FloatImage, error) {
+	p := pnmReader{bufio.NewReader(r)}
+	magic, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	ch := 3
+	switch magic {
+	case "PF":
+	case "Pf":
+		ch = 1
+	default:
+		return nil, errors.New("Not a PFM image")
+	}
+	w, err := p.int()
+	if err != nil {
+		return nil, err
+	}
+	h, err := p.int()
+	if err != nil {
+		return nil, err
+	}
+	t, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	scale, err := strconv.ParseFloat(t, 64)
+	if err != nil || scale == 0 {
+		return nil, errors.New("Invalid PFM scale: " + t)
+	}
+	if !validSize(w, h) {
+		return nil, errors.New("Invalid PFM size")
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+	f := NewFloatImage(w, h)
+	b := make([]byte, 4*ch)
+	for y := h - 1; y >= 0; y-- {
+		for x := 0; x < w; x++ {
+			if _, err := io.ReadFull(p, b); err != nil {
+				return nil, err
+			}
+			i := 4 * (y*w + x)
+			for c := 0; c < 3; c++ {
+				k := c
+				if ch == 1 {
+					k = 0
+				}
+				f.Pix[i+c] = math.Float32frombits(order.Uint32(b[4*k:]))
+			}
+		}
+	}
+	return f, nil
+}
+
+// rgbe encodes a color to Radiance RGBE format: 3 mantissas and a shared
+// exponent.
+func rgbe(r, g, b float32) [4]byte {
+	v := float64(r)
+	if float64(g) > v {
+		v = float64(g)
+	}
+	if float64(b) > v {
+		v = float64(b)
+	}
+	if v < 1e-32 {
+		return [4]byte{}
+	}
+	m, e := math.Frexp(v)
+	s := m * 256 / v
+	return [4]byte{
+		byte(math.Max(0, float64(r)*s)),
+		byte(math.Max(0, float64(g)*s)),
+		byte(math.Max(0, float64(b)*s)),
+		byte(e + 128),
+	}
+}
+
+// unrgbe decodes a color from Radiance RGBE format.
+func unrgbe(c []byte) (float32, float32, float32) {
+	if c[3] == 0 {
+		return 0, 0, 0
+	}
+	f := math.Ldexp(1, int(c[3])-(128+8))
+	return float32((float64(c[0]) + 0.5) * f),
+		float32((float64(c[1]) + 0.5) * f),
+		float32((float64(c[2]) + 0.5) * f)
+}
+
+// WriteHdr stores the image in Radiance HDR format with RGBE pixels to the
+// given writer. Alpha is dropped and negative values are clamped to 0. Each
+// pixel is stored in 4 bytes with shared exponent, so precision is lower than
+// float32 but the range is kept. Scanlines are not run-length encoded.
+func (f *FloatImage) WriteHdr(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(
+		bw,
+		"#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n",
+		f.Height,
+		f.Width,
+	)
+	for i := 0; i < len(f.Pix); i += 4 {
+		c := rgbe(f.Pix[i], f.Pix[i+1], f.Pix[i+2])
+		bw.Write(c[:])
+	}
+	return bw.Flush()
+}
+
+// readHdrLine reads a scanline of RGBE pixels, either flat or with new-style
+// run-length encoding.
+func readHdrLine(r *bufio.Reader, line []byte) error {
+	w := len(line) / 4
+	if _, err := io.ReadFull(r, line[:4]); err != nil {
+		return err
+	}
+	if w < 8 || w > 0x7fff || line[0] != 2 || line[1] != 2 ||
+		int(line[2])<<8|int(line[3]) != w {
+		// Flat, the first pixel has been read already
+		_, err := io.ReadFull(r, line[4:])
+		return err
+	}
+	// Run-length encoded, each channel separately
+	for c := 0; c < 4; c++ {
+		for x := 0; x < w; {
+			n, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if n > 128 {
+				n -= 128
+				v, err := r.ReadByte()
+				if err != nil {
+					return err
+				}
+				if x+int(n) > w {
+					return errors.New("Invalid HDR run length")
+				}
+				for ; n > 0; n-- {
+					line[4*x+c] = v
+					x++
+				}
+			} else {
+				if n == 0 || x+int(n) > w {
+					return errors.New("Invalid HDR run length")
+				}
+				for ; n > 0; n-- {
+					v, err := r.ReadByte()
+					if err != nil {
+						return err
+					}
+					line[4*x+c] = v
+					x++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ReadHdr reads an image in Radiance HDR format with RGBE pixels, flat or
+// run-length encoded. Only the standard orientation "-Y h +X w" is supported.
+// Alpha is set to 1.
+func ReadHdr(r io.Reader) (*FloatImage, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(magic, "#?") {
+		return nil, errors.New("Not a Radiance HDR image")
+	}
+	for {
+		l, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		l = strings.TrimSpace(l)
+		if l == "" {
+			break
+		}
+		if strings.HasPrefix(l, "FORMAT=") && l != "FORMAT=32-bit_rle_rgbe" {
+			return nil, errors.New("Unsupported HDR format: " + l)
+		}
+	}
+	res, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	var w, h int
+	if _, err := fmt.Sscanf(res, "-Y %d +X %d", &h, &w); err != nil {
+		return nil, errors.New("Unsupported HDR resolution: " + res)
+	}
+	if !validSize(w, h) {
+		return nil, errors.New("Invalid HDR size")
+	}
+	f := NewFloatImage(w, h)
+	line := make([]byte, 4*w)
+	for y := 0; y < h; y++ {
+		if err := readHdrLine(br, line); err != nil {
+			return nil, err
+		}
+		for x := 0; x < w; x++ {
+			i := 4 * (y*w + x)
+			f.Pix[i], f.Pix[i+1], f.Pix[i+2] = unrgbe(line[4*x:])
+		}
+	}
+	return f, nil
+}
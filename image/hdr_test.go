@@ -0,0 +1,114 @@
+package image
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFloatImage(t *testing.T) {
+	f := NewFloatImage(2, 2)
+	c := [4]float32{1.5, 2, 30, 1}
+	f.Set(1, 0, c)
+	f.Set(5, 5, c)
+	if f.At(1, 0) != c {
+		t.Errorf("expected '%v' but got '%v'", c, f.At(1, 0))
+	}
+	black := [4]float32{0, 0, 0, 1}
+	if f.At(0, 1) != black {
+		t.Errorf("expected '%v' but got '%v'", black, f.At(0, 1))
+	}
+}
+
+func TestPfm(t *testing.T) {
+	f1 := NewFloatImage(3, 2)
+	c := [4]float32{1e5, -0.25, 3.75, 1}
+	f1.Set(2, 0, c)
+	var buf bytes.Buffer
+	f1.WritePfm(&buf)
+	f2, err := ReadPfm(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f2.At(2, 0) != c {
+		t.Errorf("expected '%v' but got '%v'", c, f2.At(2, 0))
+	}
+}
+
+var rgbetests = []struct {
+	c [3]float32
+}{
+	{[3]float32{0, 0, 0}},
+	{[3]float32{1, 0.5, 0.25}},
+	{[3]float32{1000, 10, 0}},
+	{[3]float32{0.001, 0.002, 0.003}},
+}
+
+func TestRgbe(t *testing.T) {
+	for _, test := range rgbetests {
+		e := rgbe(test.c[0], test.c[1], test.c[2])
+		r, g, b := unrgbe(e[:])
+		d := [3]float32{r, g, b}
+		for i := range d {
+			// Precision is about 1/256 of the biggest component
+			if diff := d[i] - test.c[i]; diff > test.c[0]/128 || -diff > test.c[0]/128 {
+				t.Errorf("expected '%v' but got '%v'", test.c, d)
+				break
+			}
+		}
+	}
+}
+
+func TestHdr(t *testing.T) {
+	f1 := NewFloatImage(3, 2)
+	c := [4]float32{4, 2, 1, 1}
+	f1.Set(0, 1, c)
+	var buf bytes.Buffer
+	f1.WriteHdr(&buf)
+	f2, err := ReadHdr(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decoding adds half a step to the mantissas
+	r := [4]float32{4.015625, 2.015625, 1.015625, 1}
+	if f2.At(0, 1) != r {
+		t.Errorf("expected '%v' but got '%v'", r, f2.At(0, 1))
+	}
+}
+
+func TestReadHdrRle(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 1 +X 8\n")
+	buf.Write([]byte{2, 2, 0, 8})
+	// R, G and B: run of 8 times 128, E: run of 8 times 129
+	buf.Write([]byte{128 + 8, 128, 128 + 8, 128, 128 + 8, 128, 128 + 8, 129})
+	f, err := ReadHdr(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := [4]float32{1.0039062, 1.0039062, 1.0039062, 1}
+	if f.At(7, 0) != c {
+		t.Errorf("expected '%v' but got '%v'", c, f.At(7, 0))
+	}
+}
+
+func TestReadHdrInvalid(t *testing.T) {
+	pfms := []string{
+		"PF\n4000000000 4000000000\n-1.0\n",
+		"Pf\n-2 3\n-1.0\n",
+	}
+	for _, test := range pfms {
+		if _, err := ReadPfm(bytes.NewBufferString(test)); err == nil {
+			t.Errorf("expected error for '%q' but got none", test)
+		}
+	}
+	hdrs := []string{
+		"#?RADIANCE\n\n-Y -2 +X -3\n",
+		"#?RADIANCE\n\n-Y 100000 +X 100000\n",
+		"#?RADIANCE\n\n-Y 0 +X 2000000000\n",
+	}
+	for _, test := range hdrs {
+		if _, err := ReadHdr(bytes.NewBufferString(test)); err == nil {
+			t.Errorf("expected error for '%q' but got none", test)
+		}
+	}
+}
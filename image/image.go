@@ -1,10 +1,10 @@
 package image
 
 import (
-	tmath "github.com/amsibamsi/three/math"
+	"github.com/amsibamsi/three/draw"
 	"image"
 	"image/color"
-	"image/draw"
+	stddraw "image/draw"
 	"image/png"
 	"io"
 )
@@ -15,20 +15,62 @@ type Image struct {
 	Rgba image.RGBA
 }
 
+// Largest number of pixels the readers accept, to not allocate absurd amounts
+// of memory for a corrupt header
+const maxPixels = 1 << 26
+
+// validSize returns true if w and h are not negative and an image of that size
+// has no more than maxPixels pixels.
+func validSize(w, h int) bool {
+	return w >= 0 && h >= 0 && w <= maxPixels && h <= maxPixels &&
+		int64(w)*int64(h) <= maxPixels
+}
+
 // NewImage returns a new image with the given screen width and height and
 // black background.
 func NewImage(w, h int) *Image {
 	rect := image.Rect(0, 0, w, h)
 	rgba := image.NewRGBA(rect)
 	bg := image.Uniform{color.Black}
-	draw.Draw(rgba, rgba.Bounds(), &bg, image.Point{}, draw.Src)
+	stddraw.Draw(rgba, rgba.Bounds(), &bg, image.Point{}, stddraw.Src)
+	return &Image{*rgba}
+}
+
+// NewImageRgb returns a new image with the given width and height and pixels
+// copied from rgb. Each pixel is given by 3 consecutive bytes for red, green
+// and blue. Pixels go from left to right and then from top to bottom. Alpha is
+// set to opaque.
+func NewImageRgb(rgb []byte, w, h int) *Image {
+	rect := image.Rect(0, 0, w, h)
+	rgba := image.NewRGBA(rect)
+	for i, j := 0, 0; i < 3*w*h; i, j = i+3, j+4 {
+		rgba.Pix[j] = rgb[i]
+		rgba.Pix[j+1] = rgb[i+1]
+		rgba.Pix[j+2] = rgb[i+2]
+		rgba.Pix[j+3] = 255
+	}
+	return &Image{*rgba}
+}
+
+// NewImageBgra returns a new image with the given width and height and pixels
+// copied from bgra. Each pixel is given by 4 consecutive bytes for blue, green,
+// red and an ignored byte. Pixels go from left to right and then from top to
+// bottom. Alpha is set to opaque.
+func NewImageBgra(bgra []byte, w, h int) *Image {
+	rect := image.Rect(0, 0, w, h)
+	rgba := image.NewRGBA(rect)
+	for i := 0; i < 4*w*h; i += 4 {
+		rgba.Pix[i] = bgra[i+2]
+		rgba.Pix[i+1] = bgra[i+1]
+		rgba.Pix[i+2] = bgra[i]
+		rgba.Pix[i+3] = 255
+	}
 	return &Image{*rgba}
 }
 
 // DrawDot draws a clearly visible dot (more than 1 pixel) at (x,y) with the
-// given color.
+// given color. Translucent colors are drawn over the existing pixels.
 func (img *Image) DrawDot(x, y int, c color.Color) {
-	r := img.Rgba
 	ind := []int{
 		-2, 0,
 		-1, -1,
@@ -43,35 +85,14 @@ func (img *Image) DrawDot(x, y int, c color.Color) {
 		2, 0,
 	}
 	for i := 0; i < len(ind); i += 2 {
-		r.Set(x+ind[i], y+ind[i+1], c)
+		draw.Pixel(&img.Rgba, x+ind[i], y+ind[i+1], c)
 	}
 }
 
 // DrawLine draws a 1 pixel thick line between the (x1,y1) and (x2,y2) with the
-// given color.
+// given color. Translucent colors are drawn over the existing pixels.
 func (img *Image) DrawLine(x1, y1, x2, y2 int, c color.Color) {
-	r := img.Rgba
-	// Always draw from left to right (x1 <= x2)
-	if x1 > x2 {
-		x1, y1, x2, y2 = x2, y2, x1, y1
-	}
-	dx := x2 - x1
-	dy := y2 - y1
-	var steps int
-	if tmath.Absi(dx) > tmath.Absi(dy) {
-		steps = tmath.Absi(dx)
-	} else {
-		steps = tmath.Absi(dy)
-	}
-	xinc := float64(dx) / float64(steps)
-	yinc := float64(dy) / float64(steps)
-	x := float64(x1)
-	y := float64(y1)
-	for s := 0; s <= steps; s++ {
-		r.Set(tmath.Round(x), tmath.Round(y), c)
-		x += xinc
-		y += yinc
-	}
+	draw.Line(&img.Rgba, x1, y1, x2, y2, c)
 }
 
 // WritePng stores the image in PNG format to the given writer and returns the
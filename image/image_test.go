@@ -18,11 +18,38 @@ func TestNewImage(t *testing.T) {
 	}
 }
 
+func TestNewImageRgb(t *testing.T) {
+	rgb := []byte{
+		1, 2, 3, 4, 5, 6,
+		7, 8, 9, 10, 11, 12,
+	}
+	img := NewImageRgb(rgb, 2, 2)
+	col1 := color.RGBA{7, 8, 9, 255}
+	col2 := img.Rgba.At(0, 1)
+	if col1 != col2 {
+		t.Errorf("expected '%v' but got '%v'", col1, col2)
+	}
+}
+
+func TestNewImageBgra(t *testing.T) {
+	bgra := []byte{
+		1, 2, 3, 0, 4, 5, 6, 0,
+		7, 8, 9, 0, 10, 11, 12, 0,
+	}
+	img := NewImageBgra(bgra, 2, 2)
+	col1 := color.RGBA{9, 8, 7, 255}
+	col2 := img.Rgba.At(0, 1)
+	if col1 != col2 {
+		t.Errorf("expected '%v' but got '%v'", col1, col2)
+	}
+}
+
 func TestDrawDot(t *testing.T) {
 	img := NewImage(100, 100)
 	rgba := img.Rgba
-	col1 := color.RGBA{200, 111, 38, 1}
-	img.DrawDot(50, 50, col1)
+	img.DrawDot(50, 50, color.RGBA{100, 50, 20, 128})
+	// Translucent color over opaque black
+	col1 := color.RGBA{100, 50, 20, 255}
 	col2 := rgba.At(50, 50)
 	if col1 != col2 {
 		t.Errorf("expected '%v' but got '%v'", col1, col2)
@@ -32,8 +59,9 @@ func TestDrawDot(t *testing.T) {
 func TestDrawLine1(t *testing.T) {
 	img := NewImage(100, 100)
 	rgba := img.Rgba
-	col := color.RGBA{1, 2, 3, 4}
-	img.DrawLine(10, 10, 12, 12, col)
+	img.DrawLine(10, 10, 12, 12, color.RGBA{1, 2, 3, 4})
+	// Translucent color over opaque black
+	col := color.RGBA{1, 2, 3, 255}
 	should := [3]color.Color{col, col, col}
 	is := [3]color.Color{
 		rgba.At(10, 10),
@@ -48,8 +76,8 @@ func TestDrawLine1(t *testing.T) {
 func TestDrawLine2(t *testing.T) {
 	img := NewImage(100, 100)
 	rgba := img.Rgba
-	col := color.RGBA{1, 2, 3, 4}
-	img.DrawLine(10, 10, 13, 11, col)
+	img.DrawLine(10, 10, 13, 11, color.RGBA{1, 2, 3, 4})
+	col := color.RGBA{1, 2, 3, 255}
 	should := [4]color.Color{col, col, col, col}
 	is := [4]color.Color{
 		rgba.At(10, 10),
@@ -65,8 +93,8 @@ func TestDrawLine2(t *testing.T) {
 func TestDrawLine3(t *testing.T) {
 	img := NewImage(100, 100)
 	rgba := img.Rgba
-	col := color.RGBA{1, 2, 3, 4}
-	img.DrawLine(10, 10, 9, 9, col)
+	img.DrawLine(10, 10, 9, 9, color.RGBA{1, 2, 3, 4})
+	col := color.RGBA{1, 2, 3, 255}
 	should := [2]color.Color{col, col}
 	is := [2]color.Color{
 		rgba.At(9, 9),
@@ -0,0 +1,223 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// WritePpm stores the image in binary netpbm PPM format (P6) to the given
+// writer. Alpha is dropped, so translucent pixels appear as if drawn on black.
+func (img *Image) WritePpm(w io.Writer) error {
+	r := &img.Rgba
+	b := r.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P6\n%d %d\n255\n", b.Dx(), b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := r.PixOffset(x, y)
+			bw.Write(r.Pix[o : o+3])
+		}
+	}
+	return bw.Flush()
+}
+
+// WritePam stores the image in netpbm PAM format (P7) with tuple type
+// RGB_ALPHA to the given writer.
+func (img *Image) WritePam(w io.Writer) error {
+	r := &img.Rgba
+	b := r.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(
+		bw,
+		"P7\nWIDTH %d\nHEIGHT %d\nDEPTH 4\nMAXVAL 255\nTUPLTYPE RGB_ALPHA\nENDHDR\n",
+		b.Dx(),
+		b.Dy(),
+	)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(r.At(x, y)).(color.NRGBA)
+			bw.Write([]byte{c.R, c.G, c.B, c.A})
+		}
+	}
+	return bw.Flush()
+}
+
+// pnmReader reads the whitespace separated tokens of netpbm headers and skips
+// comments.
+type pnmReader struct {
+	*bufio.Reader
+}
+
+// token returns the next header token.
+func (p pnmReader) token() (string, error) {
+	var tok []byte
+	for {
+		c, err := p.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(tok) > 0 {
+				return string(tok), nil
+			}
+			return "", err
+		}
+		switch {
+		case c == '#' && len(tok) == 0:
+			if _, err := p.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(tok) > 0 {
+				return string(tok), nil
+			}
+		default:
+			tok = append(tok, c)
+		}
+	}
+}
+
+// int returns the next header token as positive integer.
+func (p pnmReader) int() (int, error) {
+	t, err := p.token()
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(t)
+	if err != nil || i < 0 {
+		return 0, errors.New("Invalid number in netpbm header: " + t)
+	}
+	return i, nil
+}
+
+// sample reads one sample with the given maximum value, either from ASCII or
+// binary data, and scales it to 8 bits.
+func (p pnmReader) sample(ascii bool, max int) (uint8, error) {
+	var v int
+	var err error
+	switch {
+	case ascii:
+		v, err = p.int()
+	case max < 256:
+		var b byte
+		b, err = p.ReadByte()
+		v = int(b)
+	default:
+		var b [2]byte
+		_, err = io.ReadFull(p, b[:])
+		v = int(b[0])<<8 | int(b[1])
+	}
+	if err != nil {
+		return 0, err
+	}
+	if v > max {
+		v = max
+	}
+	return uint8((v*255 + max/2) / max), nil
+}
+
+// ReadPpm reads an image in netpbm PPM format, either binary (P6) or ASCII
+// (P3), with up to 16 bits per sample.
+func ReadPpm(r io.Reader) (*Image, error) {
+	p := pnmReader{bufio.NewReader(r)}
+	magic, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	if magic != "P6" && magic != "P3" {
+		return nil, errors.New("Not a PPM image")
+	}
+	var dims [3]int
+	for i := range dims {
+		if dims[i], err = p.int(); err != nil {
+			return nil, err
+		}
+	}
+	w, h, max := dims[0], dims[1], dims[2]
+	if max == 0 || max > 65535 {
+		return nil, errors.New("Invalid PPM maximum value")
+	}
+	if !validSize(w, h) {
+		return nil, errors.New("Invalid PPM size")
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(rgba.Pix); i++ {
+		if i%4 == 3 {
+			rgba.Pix[i] = 255
+			continue
+		}
+		if rgba.Pix[i], err = p.sample(magic == "P3", max); err != nil {
+			return nil, err
+		}
+	}
+	return &Image{*rgba}, nil
+}
+
+// ReadPam reads an image in netpbm PAM format (P7). Supported tuple types are
+// GRAYSCALE, GRAYSCALE_ALPHA, RGB and RGB_ALPHA with up to 16 bits per sample.
+func ReadPam(r io.Reader) (*Image, error) {
+	p := pnmReader{bufio.NewReader(r)}
+	magic, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	if magic != "P7" {
+		return nil, errors.New("Not a PAM image")
+	}
+	w, h, depth, max := -1, -1, -1, -1
+	for {
+		t, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		if t == "ENDHDR" {
+			break
+		}
+		switch t {
+		case "WIDTH":
+			w, err = p.int()
+		case "HEIGHT":
+			h, err = p.int()
+		case "DEPTH":
+			depth, err = p.int()
+		case "MAXVAL":
+			max, err = p.int()
+		case "TUPLTYPE":
+			_, err = p.token()
+		default:
+			return nil, errors.New("Unknown PAM header field: " + t)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !validSize(w, h) || depth < 1 || depth > 4 || max < 1 || max > 65535 {
+		return nil, errors.New("Invalid or unsupported PAM header")
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	var s [4]uint8
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for i := 0; i < depth; i++ {
+				if s[i], err = p.sample(false, max); err != nil {
+					return nil, err
+				}
+			}
+			var c color.NRGBA
+			switch depth {
+			case 1:
+				c = color.NRGBA{s[0], s[0], s[0], 255}
+			case 2:
+				c = color.NRGBA{s[0], s[0], s[0], s[1]}
+			case 3:
+				c = color.NRGBA{s[0], s[1], s[2], 255}
+			case 4:
+				c = color.NRGBA{s[0], s[1], s[2], s[3]}
+			}
+			rgba.Set(x, y, c)
+		}
+	}
+	return &Image{*rgba}, nil
+}
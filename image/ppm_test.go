@@ -0,0 +1,79 @@
+package image
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestPpm(t *testing.T) {
+	img1 := NewImage(10, 5)
+	col := color.RGBA{200, 111, 38, 255}
+	img1.Rgba.Set(3, 4, col)
+	var buf bytes.Buffer
+	img1.WritePpm(&buf)
+	img2, err := ReadPpm(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := img2.Rgba.At(3, 4)
+	if c != col {
+		t.Errorf("expected '%v' but got '%v'", col, c)
+	}
+}
+
+func TestReadPpmAscii(t *testing.T) {
+	data := "P3\n# comment\n2 1\n15\n15 0 0  0 0 15\n"
+	img, err := ReadPpm(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	should := [2]color.Color{
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 0, 255, 255},
+	}
+	is := [2]color.Color{img.Rgba.At(0, 0), img.Rgba.At(1, 0)}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+}
+
+func TestPam(t *testing.T) {
+	img1 := NewImage(3, 3)
+	col := color.RGBA{200, 100, 50, 255}
+	img1.Rgba.Set(1, 2, col)
+	img1.Rgba.Set(2, 2, color.Transparent)
+	var buf bytes.Buffer
+	img1.WritePam(&buf)
+	img2, err := ReadPam(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	should := [2]color.Color{col, color.RGBA{}}
+	is := [2]color.Color{img2.Rgba.At(1, 2), img2.Rgba.At(2, 2)}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+}
+
+func TestReadPnmInvalid(t *testing.T) {
+	tests := []string{
+		"P6\n4000000000 4000000000\n255\n",
+		"P6\n-2 3\n255\n",
+		"P3\n100000000 0\n255\n",
+		"P7\nWIDTH 4000000000\nHEIGHT 4000000000\nDEPTH 3\nMAXVAL 255\nENDHDR\n",
+		"P7\nWIDTH -2\nHEIGHT 3\nDEPTH 3\nMAXVAL 255\nENDHDR\n",
+	}
+	for _, test := range tests {
+		var err error
+		if strings.HasPrefix(test, "P7") {
+			_, err = ReadPam(strings.NewReader(test))
+		} else {
+			_, err = ReadPpm(strings.NewReader(test))
+		}
+		if err == nil {
+			t.Errorf("expected error for '%q' but got none", test)
+		}
+	}
+}
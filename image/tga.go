@@ -0,0 +1,106 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// WriteTga stores the image in uncompressed 32 bit TGA format with alpha to the
+// given writer.
+func (img *Image) WriteTga(w io.Writer) error {
+	r := &img.Rgba
+	b := r.Bounds()
+	head := make([]byte, 18)
+	head[2] = 2 // Uncompressed true color
+	head[12] = byte(b.Dx())
+	head[13] = byte(b.Dx() >> 8)
+	head[14] = byte(b.Dy())
+	head[15] = byte(b.Dy() >> 8)
+	head[16] = 32   // Bits per pixel
+	head[17] = 0x28 // 8 alpha bits, origin at top left
+	bw := bufio.NewWriter(w)
+	bw.Write(head)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(r.At(x, y)).(color.NRGBA)
+			bw.Write([]byte{c.B, c.G, c.R, c.A})
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadTga reads an image in TGA format. Supported are true color images with
+// 24 or 32 bits per pixel and grayscale images with 8 bits per pixel, both
+// uncompressed or run-length encoded. Color-mapped images are not supported.
+func ReadTga(r io.Reader) (*Image, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, 18)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, err
+	}
+	typ := head[2]
+	rle := typ == 10 || typ == 11
+	gray := typ == 3 || typ == 11
+	if typ != 2 && typ != 3 && typ != 10 && typ != 11 {
+		return nil, errors.New("Unsupported TGA image type")
+	}
+	w := int(head[12]) | int(head[13])<<8
+	h := int(head[14]) | int(head[15])<<8
+	if !validSize(w, h) {
+		return nil, errors.New("Invalid TGA size")
+	}
+	bpp := int(head[16]) / 8
+	if gray && bpp != 1 || !gray && bpp != 3 && bpp != 4 {
+		return nil, errors.New("Unsupported TGA pixel depth")
+	}
+	topDown := head[17]&0x20 != 0
+	rightLeft := head[17]&0x10 != 0
+	// Skip image ID and color map
+	cmapLen := (int(head[5]) | int(head[6])<<8) * ((int(head[7]) + 7) / 8)
+	if _, err := br.Discard(int(head[0]) + cmapLen); err != nil {
+		return nil, err
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	px := make([]byte, bpp)
+	count, repeat := 0, false
+	for i := 0; i < w*h; i++ {
+		if rle && count == 0 {
+			c, err := br.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			count = int(c&0x7f) + 1
+			repeat = c&0x80 != 0
+			if _, err := io.ReadFull(br, px); err != nil {
+				return nil, err
+			}
+		} else if !rle || !repeat {
+			if _, err := io.ReadFull(br, px); err != nil {
+				return nil, err
+			}
+		}
+		count--
+		x := i % w
+		y := i / w
+		if !topDown {
+			y = h - 1 - y
+		}
+		if rightLeft {
+			x = w - 1 - x
+		}
+		var c color.NRGBA
+		switch bpp {
+		case 1:
+			c = color.NRGBA{px[0], px[0], px[0], 255}
+		case 3:
+			c = color.NRGBA{px[2], px[1], px[0], 255}
+		case 4:
+			c = color.NRGBA{px[2], px[1], px[0], px[3]}
+		}
+		rgba.Set(x, y, c)
+	}
+	return &Image{*rgba}, nil
+}
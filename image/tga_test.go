@@ -0,0 +1,55 @@
+package image
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestTga(t *testing.T) {
+	img1 := NewImage(4, 3)
+	col := color.RGBA{1, 2, 3, 255}
+	img1.Rgba.Set(3, 0, col)
+	var buf bytes.Buffer
+	img1.WriteTga(&buf)
+	img2, err := ReadTga(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := img2.Rgba.At(3, 0)
+	if c != col {
+		t.Errorf("expected '%v' but got '%v'", col, c)
+	}
+}
+
+func TestReadTgaRle(t *testing.T) {
+	head := make([]byte, 18)
+	head[2] = 10
+	head[12] = 3
+	head[14] = 1
+	head[16] = 24
+	// Run of 2 red pixels and 1 literal blue pixel, bottom-up
+	data := append(head, 0x81, 0, 0, 255, 0x00, 255, 0, 0)
+	img, err := ReadTga(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	should := [3]color.Color{red, red, blue}
+	is := [3]color.Color{img.Rgba.At(0, 0), img.Rgba.At(1, 0), img.Rgba.At(2, 0)}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+}
+
+func TestReadTgaInvalid(t *testing.T) {
+	head := make([]byte, 18)
+	head[2] = 2
+	head[12], head[13] = 0xff, 0xff
+	head[14], head[15] = 0xff, 0xff
+	head[16] = 32
+	if _, err := ReadTga(bytes.NewReader(head)); err == nil {
+		t.Errorf("expected error for size '%v' but got none", [2]int{65535, 65535})
+	}
+}
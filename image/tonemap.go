@@ -0,0 +1,158 @@
+package image
+
+import (
+	"image"
+	"math"
+)
+
+// ToneMap maps a linear color component of high dynamic range to the range
+// [0,1] for display. Results outside of [0,1] are clamped afterwards.
+type ToneMap func(v float32) float32
+
+// Clamp is the identity tone map, values above 1 are simply clipped.
+func Clamp(v float32) float32 {
+	return v
+}
+
+// Reinhard is the simple Reinhard operator v/(1+v). It compresses highlights
+// smoothly and never reaches 1.
+func Reinhard(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	return v / (1 + v)
+}
+
+// Aces is an approximation of the filmic ACES tone curve by Krzysztof
+// Narkowicz. It gives more contrast than Reinhard and saturates at 1.
+func Aces(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	r := (v * (2.51*v + 0.03)) / (v*(2.43*v+0.59) + 0.14)
+	if r > 1 {
+		return 1
+	}
+	return r
+}
+
+// Exposure returns a tone map that first scales values by 2^ev and then
+// applies the given tone map.
+func Exposure(ev float64, t ToneMap) ToneMap {
+	s := float32(math.Pow(2, ev))
+	return func(v float32) float32 {
+		return t(s * v)
+	}
+}
+
+// Srgb converts a linear component in [0,1] to the non-linear sRGB encoding
+// expected by displays and image files.
+func Srgb(v float32) float32 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return float32(1.055*math.Pow(float64(v), 1/2.4) - 0.055)
+}
+
+// Linear converts an sRGB encoded component in [0,1] to linear.
+func Linear(v float32) float32 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return float32(math.Pow((float64(v)+0.055)/1.055, 2.4))
+}
+
+// srgbLutSize is the number of entries in the sRGB lookup table.
+const srgbLutSize = 4096
+
+// srgbLut maps linear values in [0,1] quantized to srgbLutSize steps to 8 bit
+// sRGB values.
+var srgbLut [srgbLutSize + 1]uint8
+
+func init() {
+	for i := range srgbLut {
+		v := Srgb(float32(i) / srgbLutSize)
+		srgbLut[i] = uint8(v*255 + 0.5)
+	}
+}
+
+// srgb8 converts a linear component to an 8 bit sRGB value, clamping to [0,1].
+func srgb8(v float32) uint8 {
+	if !(v > 0) {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return srgbLut[int(v*srgbLutSize+0.5)]
+}
+
+// resolve converts the pixel at index i to 8 bit sRGB with straight alpha.
+func (f *FloatImage) resolve(i int, t ToneMap) (uint8, uint8, uint8, float32) {
+	a := f.Pix[i+3]
+	if a <= 0 {
+		return 0, 0, 0, 0
+	}
+	if a > 1 {
+		a = 1
+	}
+	return srgb8(t(f.Pix[i] / a)), srgb8(t(f.Pix[i+1] / a)), srgb8(t(f.Pix[i+2] / a)), a
+}
+
+// Resolve returns a new 8 bit image converted from the float image. Colors are
+// tone mapped with t and then encoded as sRGB. Colors of the float image are
+// taken as premultiplied by alpha.
+func (f *FloatImage) Resolve(t ToneMap) *Image {
+	rgba := image.NewRGBA(image.Rect(0, 0, f.Width, f.Height))
+	img := &Image{*rgba}
+	f.ResolveTo(img, t)
+	return img
+}
+
+// ResolveTo works like Resolve, but writes to an existing image. Only the
+// area covered by both images is written.
+func (f *FloatImage) ResolveTo(img *Image, t ToneMap) {
+	r := &img.Rgba
+	b := r.Bounds()
+	for y := 0; y < f.Height && y < b.Dy(); y++ {
+		for x := 0; x < f.Width && x < b.Dx(); x++ {
+			cr, cg, cb, a := f.resolve(4*(y*f.Width+x), t)
+			o := r.PixOffset(b.Min.X+x, b.Min.Y+y)
+			r.Pix[o] = uint8(float32(cr)*a + 0.5)
+			r.Pix[o+1] = uint8(float32(cg)*a + 0.5)
+			r.Pix[o+2] = uint8(float32(cb)*a + 0.5)
+			r.Pix[o+3] = uint8(a*255 + 0.5)
+		}
+	}
+}
+
+// ResolveRgb works like Resolve, but writes opaque pixels as 3 consecutive
+// bytes for red, green and blue into rgb, which holds w*h pixels from left to
+// right and top to bottom. Only the area covered by both is written.
+func (f *FloatImage) ResolveRgb(rgb []byte, w, h int, t ToneMap) {
+	for y := 0; y < f.Height && y < h; y++ {
+		for x := 0; x < f.Width && x < w; x++ {
+			cr, cg, cb, a := f.resolve(4*(y*f.Width+x), t)
+			o := 3 * (y*w + x)
+			rgb[o] = uint8(float32(cr)*a + 0.5)
+			rgb[o+1] = uint8(float32(cg)*a + 0.5)
+			rgb[o+2] = uint8(float32(cb)*a + 0.5)
+		}
+	}
+}
+
+// ResolveBgra works like ResolveRgb, but writes opaque pixels as 4 consecutive
+// bytes for blue, green, red and alpha into bgra. This is the layout graphics
+// hardware usually works with, so it can be uploaded without conversion.
+func (f *FloatImage) ResolveBgra(bgra []byte, w, h int, t ToneMap) {
+	for y := 0; y < f.Height && y < h; y++ {
+		for x := 0; x < f.Width && x < w; x++ {
+			cr, cg, cb, a := f.resolve(4*(y*f.Width+x), t)
+			o := 4 * (y*w + x)
+			bgra[o] = uint8(float32(cb)*a + 0.5)
+			bgra[o+1] = uint8(float32(cg)*a + 0.5)
+			bgra[o+2] = uint8(float32(cr)*a + 0.5)
+			bgra[o+3] = 255
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package image
+
+import (
+	"image/color"
+	"testing"
+)
+
+var tonemaptests = []struct {
+	t    ToneMap
+	v, r float32
+}{
+	{Clamp, 3, 3},
+	{Reinhard, 1, 0.5},
+	{Reinhard, -1, 0},
+	{Aces, 0, 0},
+	{Exposure(1, Clamp), 0.25, 0.5},
+	{Exposure(-2, Reinhard), 4, 0.5},
+}
+
+func TestToneMap(t *testing.T) {
+	for _, test := range tonemaptests {
+		r := test.t(test.v)
+		if r != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, r)
+		}
+	}
+}
+
+func TestAcesMonotonic(t *testing.T) {
+	last := float32(0)
+	for v := float32(0.01); v < 100; v *= 1.5 {
+		r := Aces(v)
+		if r < last || r > 1 {
+			t.Errorf("expected '%v' to be in [%v,1] but got '%v'", v, last, r)
+		}
+		last = r
+	}
+}
+
+var srgbtests = []struct {
+	v float32
+	r uint8
+}{
+	{-1, 0},
+	{0, 0},
+	{0.5, 188},
+	{1, 255},
+	{10, 255},
+}
+
+func TestSrgb8(t *testing.T) {
+	for _, test := range srgbtests {
+		r := srgb8(test.v)
+		if r != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, r)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	for _, v := range []float32{0, 0.001, 0.2, 0.7, 1} {
+		r := Linear(Srgb(v))
+		if d := r - v; d > 1e-5 || d < -1e-5 {
+			t.Errorf("expected '%v' but got '%v'", v, r)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	f := NewFloatImage(2, 1)
+	f.Set(0, 0, [4]float32{1, 0.5, 100, 1})
+	f.Set(1, 0, [4]float32{0.5, 0.5, 0.5, 0.5})
+	img := f.Resolve(Clamp)
+	should := [2]color.Color{
+		color.RGBA{255, 188, 255, 255},
+		color.RGBA{128, 128, 128, 128},
+	}
+	is := [2]color.Color{img.Rgba.At(0, 0), img.Rgba.At(1, 0)}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+	rgb := make([]byte, 3)
+	f.ResolveRgb(rgb, 1, 1, Reinhard)
+	r := []byte{188, 156, 254}
+	if string(rgb) != string(r) {
+		t.Errorf("expected '%v' but got '%v'", r, rgb)
+	}
+	bgra := make([]byte, 4)
+	f.ResolveBgra(bgra, 1, 1, Reinhard)
+	r = []byte{254, 156, 188, 255}
+	if string(bgra) != string(r) {
+		t.Errorf("expected '%v' but got '%v'", r, bgra)
+	}
+}
+
+// benchResolve returns a full HD float image with a gradient.
+func benchResolve() *FloatImage {
+	f := NewFloatImage(1920, 1080)
+	for i := 0; i < len(f.Pix); i += 4 {
+		v := float32(i%1000) / 100
+		f.Pix[i], f.Pix[i+1], f.Pix[i+2], f.Pix[i+3] = v, v/2, v/3, 1
+	}
+	return f
+}
+
+func BenchmarkResolveRgb(b *testing.B) {
+	f := benchResolve()
+	rgb := make([]byte, 3*f.Width*f.Height)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.ResolveRgb(rgb, f.Width, f.Height, Aces)
+	}
+}
+
+func BenchmarkResolveBgra(b *testing.B) {
+	f := benchResolve()
+	bgra := make([]byte, 4*f.Width*f.Height)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.ResolveBgra(bgra, f.Width, f.Height, Aces)
+	}
+}
@@ -0,0 +1,5 @@
+// Package loop provides a main loop that drives a window with frame timing,
+// frame rate limiting and an optional fixed timestep for simulations, and a
+// pipeline that renders frames on worker goroutines while the main thread
+// presents them.
+package loop
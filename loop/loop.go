@@ -0,0 +1,235 @@
+package loop
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Target is updated once per frame by a loop. A *window.Window is a target:
+// Update displays the frame and polls events, ShouldClose ends the loop.
+type Target interface {
+	Update()
+	ShouldClose() bool
+}
+
+// vsyncer is implemented by targets that can switch V-Sync on and off.
+type vsyncer interface {
+	SetVsync(on bool)
+}
+
+// Stats records the durations of the most recent frames. It keeps a fixed
+// number of frame times and overwrites the oldest one when full.
+type Stats struct {
+
+	// Ring buffer of frame times
+	times []time.Duration
+
+	// Index in times for the next frame time
+	next int
+
+	// Number of valid frame times in the buffer
+	n int
+}
+
+// NewStats returns new stats that keep the last n frame times.
+func NewStats(n int) *Stats {
+	if n < 1 {
+		n = 1
+	}
+	return &Stats{times: make([]time.Duration, n)}
+}
+
+// Add records the duration of a frame.
+func (s *Stats) Add(d time.Duration) {
+	s.times[s.next] = d
+	s.next = (s.next + 1) % len(s.times)
+	if s.n < len(s.times) {
+		s.n++
+	}
+}
+
+// Len returns the number of recorded frame times.
+func (s *Stats) Len() int {
+	return s.n
+}
+
+// Last returns the duration of the most recent frame, or 0 if there is none.
+func (s *Stats) Last() time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	return s.times[(s.next+len(s.times)-1)%len(s.times)]
+}
+
+// Mean returns the average frame time, or 0 if no frames were recorded.
+func (s *Stats) Mean() time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for i := 0; i < s.n; i++ {
+		sum += s.times[i]
+	}
+	return sum / time.Duration(s.n)
+}
+
+// Fps returns the average number of frames per second, or 0 if no frames were
+// recorded.
+func (s *Stats) Fps() float64 {
+	m := s.Mean()
+	if m <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(m)
+}
+
+// durations sorts frame times in ascending order.
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// Percentile returns the frame time below or at which p percent of the
+// recorded frames lie (nearest rank). E.g. Percentile(99) gives the worst frame
+// time ignoring the slowest percent of frames. Returns 0 if no frames were
+// recorded.
+func (s *Stats) Percentile(p float64) time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	d := make(durations, s.n)
+	copy(d, s.times[:s.n])
+	sort.Sort(d)
+	r := int(math.Ceil(p/100*float64(s.n))) - 1
+	if r < 0 {
+		r = 0
+	}
+	if r >= s.n {
+		r = s.n - 1
+	}
+	return d[r]
+}
+
+// Loop runs frames on a target until it should close.
+//
+// Each frame the loop does the following in order listed:
+//
+//   1. Measures the time since the previous frame
+//   2. Calls Update, either once with the frame time or as many times as
+//      fixed steps fit into the elapsed time
+//   3. Calls Render
+//   4. Updates the target, which displays the frame
+//   5. Sleeps if needed to not exceed the target frame rate
+//
+type Loop struct {
+
+	// Target to update every frame
+	Target Target
+
+	// Fps is the target frame rate. If > 0 the loop sleeps after each frame so
+	// that the frame rate is not exceeded. This is useful with V-Sync off.
+	Fps float64
+
+	// NoVsync switches V-Sync off if the target supports it. Frames are then
+	// displayed as fast as possible or limited by Fps. This overrides the
+	// target's own setting, e.g. a window's Options.Interval. If false the
+	// loop leaves the target's setting as it is.
+	NoVsync bool
+
+	// Step is the fixed timestep for Update. If > 0 Update is always called with
+	// this duration, possibly several or zero times per frame, and Render gets
+	// the fraction of a step that has not been simulated yet to interpolate. If
+	// 0 Update is called once per frame with the frame time.
+	Step time.Duration
+
+	// MaxSteps limits the number of fixed steps per frame. If the simulation
+	// can't keep up, remaining time is dropped instead of piling up.
+	MaxSteps int
+
+	// Update advances the simulation by dt. Optional.
+	Update func(dt time.Duration)
+
+	// Render draws the current frame. Alpha is in [0,1) and tells how far
+	// the displayed time is between the last and the next fixed step. Without
+	// fixed timestep alpha is always 0. Optional.
+	Render func(alpha float64)
+
+	// Close is checked before every frame in addition to the target's
+	// ShouldClose. Optional.
+	Close func() bool
+
+	// Stats of the recent frame times
+	Stats *Stats
+
+	// Clock, can be replaced for testing
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewLoop returns a new loop for the target with no frame rate limit, no fixed
+// timestep and stats for the last 120 frames.
+func NewLoop(t Target) *Loop {
+	return &Loop{
+		Target:   t,
+		MaxSteps: 10,
+		Stats:    NewStats(120),
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// done returns true if the loop should stop.
+func (l *Loop) done() bool {
+	return l.Target.ShouldClose() || (l.Close != nil && l.Close())
+}
+
+// Run runs frames until the target should close or Close returns true.
+func (l *Loop) Run() {
+	if v, ok := l.Target.(vsyncer); ok && l.NoVsync {
+		v.SetVsync(false)
+	}
+	var acc time.Duration
+	last := l.now()
+	first := true
+	for !l.done() {
+		start := l.now()
+		dt := start.Sub(last)
+		last = start
+		if first {
+			dt = 0
+			first = false
+		} else {
+			l.Stats.Add(dt)
+		}
+		alpha := 0.0
+		if l.Step > 0 {
+			acc += dt
+			steps := 0
+			for acc >= l.Step && steps < l.MaxSteps {
+				if l.Update != nil {
+					l.Update(l.Step)
+				}
+				acc -= l.Step
+				steps++
+			}
+			if acc >= l.Step {
+				acc = acc % l.Step
+			}
+			alpha = float64(acc) / float64(l.Step)
+		} else if l.Update != nil {
+			l.Update(dt)
+		}
+		if l.Render != nil {
+			l.Render(alpha)
+		}
+		l.Target.Update()
+		if l.Fps > 0 {
+			frame := time.Duration(float64(time.Second) / l.Fps)
+			if el := l.now().Sub(start); el < frame {
+				l.sleep(frame - el)
+			}
+		}
+	}
+}
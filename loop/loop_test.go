@@ -0,0 +1,144 @@
+package loop
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeTarget closes after a number of frames and advances a fake clock by a
+// fixed frame time on every update.
+type fakeTarget struct {
+	frames int
+	clock  *time.Time
+	frame  time.Duration
+	vsync  bool
+}
+
+func (t *fakeTarget) Update() {
+	t.frames--
+	*t.clock = t.clock.Add(t.frame)
+}
+
+func (t *fakeTarget) ShouldClose() bool {
+	return t.frames <= 0
+}
+
+func (t *fakeTarget) SetVsync(on bool) {
+	t.vsync = on
+}
+
+func newFakeLoop(frames int, frame time.Duration) (*Loop, *fakeTarget) {
+	clock := time.Unix(0, 0)
+	t := &fakeTarget{frames: frames, clock: &clock, frame: frame}
+	l := NewLoop(t)
+	l.now = func() time.Time { return clock }
+	l.sleep = func(d time.Duration) { clock = clock.Add(d) }
+	return l, t
+}
+
+var percentiletests = []struct {
+	p   float64
+	dur time.Duration
+}{
+	{0, 1},
+	{50, 5},
+	{90, 9},
+	{99, 10},
+	{100, 10},
+}
+
+func TestPercentile(t *testing.T) {
+	s := NewStats(10)
+	for _, d := range []time.Duration{7, 3, 10, 1, 5, 2, 9, 4, 8, 6} {
+		s.Add(d)
+	}
+	for _, test := range percentiletests {
+		d := s.Percentile(test.p)
+		if d != test.dur {
+			t.Errorf("expected '%v' but got '%v'", test.dur, d)
+		}
+	}
+}
+
+func TestStatsRing(t *testing.T) {
+	s := NewStats(2)
+	s.Add(time.Second)
+	s.Add(3 * time.Second)
+	s.Add(5 * time.Second)
+	if s.Len() != 2 {
+		t.Errorf("expected '%v' but got '%v'", 2, s.Len())
+	}
+	if s.Last() != 5*time.Second {
+		t.Errorf("expected '%v' but got '%v'", 5*time.Second, s.Last())
+	}
+	if s.Mean() != 4*time.Second {
+		t.Errorf("expected '%v' but got '%v'", 4*time.Second, s.Mean())
+	}
+	if s.Fps() != 0.25 {
+		t.Errorf("expected '%v' but got '%v'", 0.25, s.Fps())
+	}
+}
+
+func TestRunVariable(t *testing.T) {
+	l, target := newFakeLoop(5, 20*time.Millisecond)
+	var total time.Duration
+	updates := 0
+	l.Update = func(dt time.Duration) {
+		total += dt
+		updates++
+	}
+	l.Run()
+	if updates != 5 {
+		t.Errorf("expected '%v' updates but got '%v'", 5, updates)
+	}
+	if total != 80*time.Millisecond {
+		t.Errorf("expected '%v' but got '%v'", 80*time.Millisecond, total)
+	}
+	// Without NoVsync the target's setting is left as it is
+	if target.vsync {
+		t.Errorf("expected V-Sync to be left alone")
+	}
+	if l.Stats.Fps() != 50 {
+		t.Errorf("expected '%v' but got '%v'", 50, l.Stats.Fps())
+	}
+}
+
+func TestRunFixed(t *testing.T) {
+	l, _ := newFakeLoop(4, 25*time.Millisecond)
+	l.Step = 10 * time.Millisecond
+	updates := 0
+	l.Update = func(dt time.Duration) {
+		if dt != l.Step {
+			t.Errorf("expected '%v' but got '%v'", l.Step, dt)
+		}
+		updates++
+	}
+	var alphas []float64
+	l.Render = func(alpha float64) {
+		alphas = append(alphas, alpha)
+	}
+	l.Run()
+	if updates != 7 {
+		t.Errorf("expected '%v' updates but got '%v'", 7, updates)
+	}
+	r := []float64{0, 0.5, 0, 0.5}
+	for i := range r {
+		if alphas[i] != r[i] {
+			t.Errorf("expected '%v' but got '%v'", r, alphas)
+			break
+		}
+	}
+}
+
+func TestRunFps(t *testing.T) {
+	l, target := newFakeLoop(3, 5*time.Millisecond)
+	l.Fps = 50
+	l.NoVsync = true
+	l.Run()
+	if target.vsync {
+		t.Errorf("expected V-Sync to be off")
+	}
+	if l.Stats.Mean() != 20*time.Millisecond {
+		t.Errorf("expected '%v' but got '%v'", 20*time.Millisecond, l.Stats.Mean())
+	}
+}
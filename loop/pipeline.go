@@ -0,0 +1,224 @@
+package loop
+
+import (
+	"errors"
+	"sync"
+)
+
+// frame is a buffer with the number of the frame it holds.
+type frame struct {
+	seq int
+	buf interface{}
+}
+
+// job is a frame to render.
+type job struct {
+	frame
+	render func(buf interface{}) interface{}
+}
+
+// Pipeline renders frames on worker goroutines while the calling goroutine,
+// usually the main thread with the window, only presents finished frames. So
+// rendering the next frame overlaps with presenting the previous one instead
+// of waiting for it.
+//
+// Frames are rendered into a fixed number of buffers, e.g. framebuffers. With 2
+// buffers one frame can be rendered while another one is presented (double
+// buffering), with 3 a second frame can be rendered ahead (triple buffering).
+// More buffers allow more frames in flight with more latency.
+//
+// A frame goes through these steps:
+//
+//   1. Submit takes a free buffer and queues a render function for it
+//   2. A worker calls the render function with the buffer
+//   3. Present calls a present function with the newest rendered buffer, e.g.
+//      to copy it to a window, and frees it again
+//
+// Present always shows the newest frame. Older frames that were rendered but
+// not yet presented are dropped. Render functions run concurrently with the
+// submitting goroutine and, with more than 1 worker, with each other, so they
+// must not share state that is changed elsewhere without synchronization.
+// Usually the state to render is copied when submitting.
+type Pipeline struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// Buffers not in use
+	free []interface{}
+
+	// Rendered frames not presented yet, ordered by number
+	ready []frame
+
+	// Number of frames queued or being rendered
+	rendering int
+
+	// Number of the next frame submitted and of the last frame presented
+	next, shown int
+
+	// Number of frames rendered but never presented
+	dropped int
+
+	// Queue of frames to render, and workers taking from it
+	jobs chan job
+	wg   sync.WaitGroup
+}
+
+// NewPipeline returns a new pipeline rendering into the given buffers with the
+// given number of worker goroutines. At least 2 buffers are needed so that a
+// frame can be rendered while another one is presented. Workers is at least 1,
+// 1 renders the frames one after the other.
+func NewPipeline(buffers []interface{}, workers int) (*Pipeline, error) {
+	if len(buffers) < 2 {
+		return nil, errors.New("Need at least 2 buffers")
+	}
+	if workers < 1 {
+		return nil, errors.New("Workers must not be < 1")
+	}
+	p := &Pipeline{
+		free:  append([]interface{}{}, buffers...),
+		shown: -1,
+		jobs:  make(chan job, len(buffers)),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p, nil
+}
+
+// work renders queued frames until the pipeline is closed.
+func (p *Pipeline) work() {
+	defer p.wg.Done()
+	for j := range p.jobs {
+		buf := j.render(j.buf)
+		p.mu.Lock()
+		p.rendering--
+		if j.seq < p.shown {
+			// A newer frame was presented meanwhile
+			p.free = append(p.free, buf)
+			p.dropped++
+		} else {
+			i := len(p.ready)
+			for i > 0 && p.ready[i-1].seq > j.seq {
+				i--
+			}
+			p.ready = append(p.ready, frame{})
+			copy(p.ready[i+1:], p.ready[i:])
+			p.ready[i] = frame{j.seq, buf}
+		}
+		p.cond.Broadcast()
+		p.mu.Unlock()
+	}
+}
+
+// take returns a free buffer, or reuses the oldest rendered frame if there is
+// a newer one, since that would be dropped by Present anyway. Otherwise it
+// waits until a buffer is free if wait is true or returns false. The lock must
+// be held.
+func (p *Pipeline) take(wait bool) (interface{}, bool) {
+	for {
+		if n := len(p.free); n > 0 {
+			buf := p.free[n-1]
+			p.free = p.free[:n-1]
+			return buf, true
+		}
+		if len(p.ready) > 1 {
+			buf := p.ready[0].buf
+			p.ready = p.ready[1:]
+			p.dropped++
+			return buf, true
+		}
+		if !wait {
+			return nil, false
+		}
+		// Buffers are being rendered or presented and will be available
+		p.cond.Wait()
+	}
+}
+
+// submit queues render for the next frame as described for Submit. It returns
+// false if no buffer is free and wait is false.
+func (p *Pipeline) submit(render func(buf interface{}) interface{}, wait bool) bool {
+	p.mu.Lock()
+	buf, ok := p.take(wait)
+	if !ok {
+		p.mu.Unlock()
+		return false
+	}
+	j := job{frame{p.next, buf}, render}
+	p.next++
+	p.rendering++
+	p.mu.Unlock()
+	// Never blocks since there are at most as many jobs as buffers
+	p.jobs <- j
+	return true
+}
+
+// Submit queues a new frame to be rendered by calling render with a buffer
+// on a worker. Render returns the buffer to present, usually the given one, or
+// a new one, e.g. after the size changed, which then replaces it. If all
+// buffers are in use Submit blocks until a frame is rendered, so the
+// submitting goroutine can't get ahead of rendering by more than the number of
+// buffers. Must not be called after Close.
+func (p *Pipeline) Submit(render func(buf interface{}) interface{}) {
+	p.submit(render, true)
+}
+
+// TrySubmit is like Submit but returns false without queueing the frame if all
+// buffers are in use instead of blocking. This keeps the submitting goroutine
+// running, e.g. to present frames and handle input, when rendering is slower.
+func (p *Pipeline) TrySubmit(render func(buf interface{}) interface{}) bool {
+	return p.submit(render, false)
+}
+
+// Present calls present with the buffer of the newest rendered frame and frees
+// it afterwards. Older rendered frames are dropped. It returns false without
+// calling present if no new frame was rendered since the last call.
+func (p *Pipeline) Present(present func(buf interface{})) bool {
+	p.mu.Lock()
+	n := len(p.ready)
+	if n == 0 {
+		p.mu.Unlock()
+		return false
+	}
+	f := p.ready[n-1]
+	for _, old := range p.ready[:n-1] {
+		p.free = append(p.free, old.buf)
+	}
+	p.dropped += n - 1
+	p.ready = nil
+	p.shown = f.seq
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	present(f.buf)
+	p.mu.Lock()
+	p.free = append(p.free, f.buf)
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	return true
+}
+
+// Wait blocks until all submitted frames are rendered, e.g. to present the
+// last frame before quitting.
+func (p *Pipeline) Wait() {
+	p.mu.Lock()
+	for p.rendering > 0 {
+		p.cond.Wait()
+	}
+	p.mu.Unlock()
+}
+
+// Dropped returns the number of frames that were rendered but never presented
+// because a newer frame was ready first.
+func (p *Pipeline) Dropped() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dropped
+}
+
+// Close waits for all submitted frames to be rendered and stops the workers.
+func (p *Pipeline) Close() {
+	close(p.jobs)
+	p.wg.Wait()
+}
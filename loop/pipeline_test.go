@@ -0,0 +1,119 @@
+package loop
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// counter is a buffer that records the frame rendered into it.
+type counter struct {
+	frame int
+}
+
+// counters returns n new counter buffers.
+func counters(n int) []interface{} {
+	bufs := make([]interface{}, n)
+	for i := range bufs {
+		bufs[i] = &counter{-1}
+	}
+	return bufs
+}
+
+var newpipelinetests = []struct {
+	buffers, workers int
+	ok               bool
+}{
+	{0, 1, false},
+	{1, 1, false},
+	{2, 0, false},
+	{2, 1, true},
+	{3, 4, true},
+}
+
+func TestNewPipeline(t *testing.T) {
+	for _, test := range newpipelinetests {
+		p, err := NewPipeline(counters(test.buffers), test.workers)
+		if (err == nil) != test.ok {
+			t.Errorf("expected '%v' but got '%v'", test.ok, err == nil)
+		}
+		if p != nil {
+			p.Close()
+		}
+	}
+}
+
+func TestPipelineOrder(t *testing.T) {
+	for _, workers := range []int{1, 3} {
+		p, _ := NewPipeline(counters(3), workers)
+		r := rand.New(rand.NewSource(0))
+		last := -1
+		present := func(buf interface{}) {
+			c := buf.(*counter)
+			if c.frame <= last {
+				t.Errorf("expected frame after '%v' but got '%v'", last, c.frame)
+			}
+			last = c.frame
+		}
+		for i := 0; i < 100; i++ {
+			n := i
+			d := time.Duration(r.Intn(100)) * time.Microsecond
+			p.Submit(func(buf interface{}) interface{} {
+				time.Sleep(d)
+				buf.(*counter).frame = n
+				return buf
+			})
+			p.Present(present)
+		}
+		p.Wait()
+		p.Present(present)
+		if last != 99 {
+			t.Errorf("expected '%v' but got '%v'", 99, last)
+		}
+		p.Close()
+	}
+}
+
+func TestTrySubmit(t *testing.T) {
+	p, _ := NewPipeline(counters(2), 1)
+	release := make(chan bool)
+	render := func(buf interface{}) interface{} {
+		<-release
+		return buf
+	}
+	for i, should := range []bool{true, true, false} {
+		if ok := p.TrySubmit(render); ok != should {
+			t.Errorf("expected '%v' for frame %v but got '%v'", should, i, ok)
+		}
+	}
+	if p.Present(func(interface{}) {}) {
+		t.Errorf("expected no frame to present")
+	}
+	close(release)
+	p.Wait()
+	// The older frame is dropped
+	if !p.Present(func(interface{}) {}) {
+		t.Errorf("expected a frame to present")
+	}
+	if n := p.Dropped(); n != 1 {
+		t.Errorf("expected '%v' but got '%v'", 1, n)
+	}
+	p.Close()
+}
+
+func TestSubmitReplace(t *testing.T) {
+	p, _ := NewPipeline(counters(2), 1)
+	big := &counter{7}
+	p.Submit(func(buf interface{}) interface{} {
+		return big
+	})
+	p.Wait()
+	var got interface{}
+	p.Present(func(buf interface{}) {
+		got = buf
+	})
+	if got != big {
+		t.Errorf("expected '%v' but got '%v'", big, got)
+	}
+	p.Close()
+}
@@ -0,0 +1,85 @@
+package geom
+
+import (
+	"math"
+)
+
+// Box is an axis-aligned box given by its minimum and maximum corner.
+type Box struct {
+	Min, Max Vec3
+}
+
+// EmptyBox returns a new box that contains nothing. Adding a point makes it the
+// box containing just that point.
+func EmptyBox() *Box {
+	inf := math.Inf(1)
+	return &Box{Vec3{inf, inf, inf}, Vec3{-inf, -inf, -inf}}
+}
+
+// Empty returns true if the box contains nothing.
+func (b *Box) Empty() bool {
+	return b.Min[0] > b.Max[0] || b.Min[1] > b.Max[1] || b.Min[2] > b.Max[2]
+}
+
+// AddPoint grows the box to contain point p.
+func (b *Box) AddPoint(p *Vec3) {
+	for k := 0; k < 3; k++ {
+		b.Min[k] = math.Min(b.Min[k], p[k])
+		b.Max[k] = math.Max(b.Max[k], p[k])
+	}
+}
+
+// AddBox grows the box to contain box o.
+func (b *Box) AddBox(o *Box) {
+	for k := 0; k < 3; k++ {
+		b.Min[k] = math.Min(b.Min[k], o.Min[k])
+		b.Max[k] = math.Max(b.Max[k], o.Max[k])
+	}
+}
+
+// Center returns the center of the box.
+func (b *Box) Center() *Vec3 {
+	return &Vec3{
+		(b.Min[0] + b.Max[0]) / 2,
+		(b.Min[1] + b.Max[1]) / 2,
+		(b.Min[2] + b.Max[2]) / 2,
+	}
+}
+
+// Area returns the surface area of the box, 0 if it is empty.
+func (b *Box) Area() float64 {
+	if b.Empty() {
+		return 0
+	}
+	dx := b.Max[0] - b.Min[0]
+	dy := b.Max[1] - b.Min[1]
+	dz := b.Max[2] - b.Min[2]
+	return 2 * (dx*dy + dy*dz + dz*dx)
+}
+
+// Overlaps returns true if the box and box o have any point in common.
+func (b *Box) Overlaps(o *Box) bool {
+	for k := 0; k < 3; k++ {
+		if b.Min[k] > o.Max[k] || b.Max[k] < o.Min[k] {
+			return false
+		}
+	}
+	return !b.Empty() && !o.Empty()
+}
+
+// Outside returns true if the whole box is on the negative side of plane p.
+// An empty box is always outside.
+func (b *Box) Outside(p *Plane) bool {
+	if b.Empty() {
+		return true
+	}
+	// Corner farthest in the direction of the normal
+	var c Vec3
+	for k := 0; k < 3; k++ {
+		c[k] = b.Min[k]
+		if p.Normal[k] > 0 {
+			c[k] = b.Max[k]
+		}
+	}
+	return p.Dist(&c) < 0
+}
@@ -0,0 +1,63 @@
+package geom
+
+import (
+	"testing"
+)
+
+func TestBox(t *testing.T) {
+	b := EmptyBox()
+	if !b.Empty() || b.Area() != 0 {
+		t.Errorf("expected empty box")
+	}
+	b.AddPoint(&Vec3{1, 0, 0})
+	b.AddPoint(&Vec3{0, 2, 3})
+	r := Box{Vec3{0, 0, 0}, Vec3{1, 2, 3}}
+	if *b != r {
+		t.Errorf("expected '%v' but got '%v'", r, *b)
+	}
+	if a := b.Area(); a != 22 {
+		t.Errorf("expected '%v' but got '%v'", 22, a)
+	}
+	if c := *b.Center(); c != (Vec3{0.5, 1, 1.5}) {
+		t.Errorf("expected '%v' but got '%v'", Vec3{0.5, 1, 1.5}, c)
+	}
+	b.AddBox(&Box{Vec3{-1, 0, 0}, Vec3{0, 0, 4}})
+	r = Box{Vec3{-1, 0, 0}, Vec3{1, 2, 4}}
+	if *b != r {
+		t.Errorf("expected '%v' but got '%v'", r, *b)
+	}
+}
+
+var overlaptests = []struct {
+	a, b Box
+	ok   bool
+}{
+	{Box{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Box{Vec3{0.5, 0.5, 0.5}, Vec3{2, 2, 2}}, true},
+	{Box{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Box{Vec3{1, 1, 1}, Vec3{2, 2, 2}}, true},
+	{Box{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Box{Vec3{0, 2, 0}, Vec3{1, 3, 1}}, false},
+	{Box{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, *EmptyBox(), false},
+}
+
+func TestOverlaps(t *testing.T) {
+	for _, test := range overlaptests {
+		if ok := test.a.Overlaps(&test.b); ok != test.ok {
+			t.Errorf("expected '%v' but got '%v'", test.ok, ok)
+		}
+	}
+}
+
+func TestOutside(t *testing.T) {
+	// Plane x = 2 with normal towards +x
+	p := Plane{Vec3{1, 0, 0}, -2}
+	b := Box{Vec3{0, 0, 0}, Vec3{1, 1, 1}}
+	if !b.Outside(&p) {
+		t.Errorf("expected box outside")
+	}
+	b.Max[0] = 3
+	if b.Outside(&p) {
+		t.Errorf("expected box not outside")
+	}
+	if !EmptyBox().Outside(&p) {
+		t.Errorf("expected empty box outside")
+	}
+}
@@ -3,6 +3,8 @@ package geom
 import (
 	"math"
 	"math/rand"
+	"runtime"
+	"sync"
 )
 
 // Vec2 is a vector in 2D space with cartesian coordinates. Holds 2 components:
@@ -51,6 +53,16 @@ func (v *Vec3) Scale(s float64) {
 	v[2] *= s
 }
 
+// Len returns the length of the vector.
+func (v *Vec3) Len() float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
+
+// Dot returns the dot product of the two vectors.
+func Dot(v, w *Vec3) float64 {
+	return v[0]*w[0] + v[1]*w[1] + v[2]*w[2]
+}
+
 // Cross returns a new vector that is the cross product of the two vectors.
 func Cross(v, w *Vec3) *Vec3 {
 	return &Vec3{
@@ -94,6 +106,16 @@ func ZeroMat() *Mat4 {
 	}
 }
 
+// IdentMat returns a new identity matrix.
+func IdentMat() *Mat4 {
+	return &Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
 // RandMat returns a new matrix random values.
 func RandMat(r *rand.Rand) *Mat4 {
 	m := Mat4{}
@@ -120,10 +142,127 @@ func (m *Mat4) Mul(n *Mat4) {
 // given vector.
 func (m *Mat4) Transf(v *Vec4) *Vec4 {
 	p := Vec4{0, 0, 0, 0}
-	for i := 0; i < 4; i++ {
-		for j := 0; j < 4; j++ {
-			p[i] += m[i*4+j] * v[j]
+	m.TransfTo(&p, v)
+	return &p
+}
+
+// TransfTo multiplies the matrix with vector v and stores the result in dst
+// without allocating. Dst may be the same as v.
+func (m *Mat4) TransfTo(dst, v *Vec4) {
+	x, y, z, w := v[0], v[1], v[2], v[3]
+	dst[0] = m[0]*x + m[1]*y + m[2]*z + m[3]*w
+	dst[1] = m[4]*x + m[5]*y + m[6]*z + m[7]*w
+	dst[2] = m[8]*x + m[9]*y + m[10]*z + m[11]*w
+	dst[3] = m[12]*x + m[13]*y + m[14]*z + m[15]*w
+}
+
+// TransfAll transforms every vector of src and stores it at the same index in
+// dst, which must be at least as long as src. Dst may be the same as src.
+func (m *Mat4) TransfAll(dst, src []Vec4) {
+	dst = dst[:len(src)]
+	for i := range src {
+		m.TransfTo(&dst[i], &src[i])
+	}
+}
+
+// TransfPoints transforms every point of src in cartesian coordinates like a
+// homogeneous vector with w 1 and stores the normalized result at the same
+// index in dst, which must be at least as long as src. Dst may be the same as
+// src.
+func (m *Mat4) TransfPoints(dst, src []Vec3) {
+	dst = dst[:len(src)]
+	for i := range src {
+		p := Vec4{src[i][0], src[i][1], src[i][2], 1}
+		m.TransfTo(&p, &p)
+		p.Norm()
+		dst[i] = Vec3{p[0], p[1], p[2]}
+	}
+}
+
+// parMin is the least number of vectors per goroutine when transforming in
+// parallel, fewer are not worth starting a goroutine for.
+const parMin = 1024
+
+// parallel splits the range from 0 to n into consecutive chunks and calls f
+// for each chunk in its own goroutine, using at most the given number of
+// goroutines or GOMAXPROCS if 0. It returns when all calls returned.
+func parallel(n, workers int, f func(start, end int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if max := n / parMin; workers > max {
+		workers = max
+	}
+	if workers <= 1 {
+		f(0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			f(start, end)
+		}(k*n/workers, (k+1)*n/workers)
+	}
+	wg.Wait()
+}
+
+// ParTransfAll is like TransfAll but splits the vectors into chunks that are
+// transformed in parallel by the given number of goroutines, or GOMAXPROCS if
+// 0. The result is the same.
+func (m *Mat4) ParTransfAll(dst, src []Vec4, workers int) {
+	dst = dst[:len(src)]
+	parallel(len(src), workers, func(start, end int) {
+		m.TransfAll(dst[start:end], src[start:end])
+	})
+}
+
+// ParTransfPoints is like TransfPoints but splits the points into chunks that
+// are transformed in parallel by the given number of goroutines, or GOMAXPROCS
+// if 0. The result is the same.
+func (m *Mat4) ParTransfPoints(dst, src []Vec3, workers int) {
+	dst = dst[:len(src)]
+	parallel(len(src), workers, func(start, end int) {
+		m.TransfPoints(dst[start:end], src[start:end])
+	})
+}
+
+// Inv returns a new matrix that is the inverse of the matrix. It returns false
+// if the matrix is singular and has no inverse.
+func (m *Mat4) Inv() (*Mat4, bool) {
+	a := *m
+	inv := IdentMat()
+	for c := 0; c < 4; c++ {
+		// Pivot with the largest absolute value for numerical stability
+		p := c
+		for r := c + 1; r < 4; r++ {
+			if math.Abs(a[r*4+c]) > math.Abs(a[p*4+c]) {
+				p = r
+			}
+		}
+		if a[p*4+c] == 0 {
+			return nil, false
+		}
+		for k := 0; k < 4; k++ {
+			a[c*4+k], a[p*4+k] = a[p*4+k], a[c*4+k]
+			inv[c*4+k], inv[p*4+k] = inv[p*4+k], inv[c*4+k]
+		}
+		d := a[c*4+c]
+		for k := 0; k < 4; k++ {
+			a[c*4+k] /= d
+			inv[c*4+k] /= d
+		}
+		for r := 0; r < 4; r++ {
+			if r == c {
+				continue
+			}
+			f := a[r*4+c]
+			for k := 0; k < 4; k++ {
+				a[r*4+k] -= f * a[c*4+k]
+				inv[r*4+k] -= f * inv[c*4+k]
+			}
 		}
 	}
-	return &p
+	return inv, true
 }
@@ -1,6 +1,7 @@
 package geom
 
 import (
+	"math"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -72,6 +73,23 @@ func TestCross(t *testing.T) {
 	}
 }
 
+func TestLen(t *testing.T) {
+	v := Vec3{2, -3, 6}
+	l := v.Len()
+	if l != 7 {
+		t.Errorf("expected '%v' but got '%v'", 7, l)
+	}
+}
+
+func TestDot(t *testing.T) {
+	v := Vec3{1, 2, 3}
+	w := Vec3{4, -5, 6}
+	d := Dot(&v, &w)
+	if d != 12 {
+		t.Errorf("expected '%v' but got '%v'", 12, d)
+	}
+}
+
 func TestNewVec4(t *testing.T) {
 	v := *NewVec4(1, 2, 3)
 	r := Vec4{1, 2, 3, 1}
@@ -89,6 +107,15 @@ func TestNorm4(t *testing.T) {
 	}
 }
 
+func TestIdentMat(t *testing.T) {
+	m := Mat4{0, 3, 0, 1, 6, 3, 5, 3, 7, 4, 8, 7, 3, 6, 0, 3}
+	r := m
+	m.Mul(IdentMat())
+	if m != r {
+		t.Errorf("expected '%v' but got '%v'", r, m)
+	}
+}
+
 func TestRandMat(t *testing.T) {
 	r := rand.New(rand.NewSource(0))
 	m := *RandMat(r)
@@ -119,6 +146,25 @@ func TestTransf(t *testing.T) {
 	}
 }
 
+func TestInv(t *testing.T) {
+	m := Mat4{0, 3, 0, 1, 6, 3, 5, 3, 7, 4, 8, 7, 3, 6, 0, 3}
+	inv, ok := m.Inv()
+	if !ok {
+		t.Fatal("expected inverse")
+	}
+	m.Mul(inv)
+	for i, v := range IdentMat() {
+		if math.Abs(m[i]-v) > 1e-12 {
+			t.Errorf("expected '%v' but got '%v'", *IdentMat(), m)
+			break
+		}
+	}
+	s := Mat4{1, 2, 3, 4, 2, 4, 6, 8, 0, 1, 0, 1, 1, 0, 0, 1}
+	if _, ok := s.Inv(); ok {
+		t.Errorf("expected no inverse for singular matrix")
+	}
+}
+
 func BenchmarkMul(b *testing.B) {
 	r := rand.New(rand.NewSource(0))
 	m := RandMat(r)
@@ -128,3 +174,109 @@ func BenchmarkMul(b *testing.B) {
 		m.Mul(n)
 	}
 }
+
+// randVecs returns n random homogeneous vectors with w 1.
+func randVecs(r *rand.Rand, n int) []Vec4 {
+	vs := make([]Vec4, n)
+	for i := range vs {
+		vs[i] = *NewVec4(r.Float64(), r.Float64(), r.Float64())
+	}
+	return vs
+}
+
+func TestTransfTo(t *testing.T) {
+	m := Mat4{1, 3, 2, 2, 9, 10, 1, 9, 0, 4, 5, 1, 6, 8, 5, 8}
+	v := Vec4{10, 7, 0, 8}
+	r := Vec4{47, 232, 36, 180}
+	m.TransfTo(&v, &v)
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestTransfAll(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	src := randVecs(r, 5000)
+	for _, workers := range []int{-1, 0, 1, 3, 8} {
+		dst := make([]Vec4, len(src))
+		if workers < 0 {
+			m.TransfAll(dst, src)
+		} else {
+			m.ParTransfAll(dst, src, workers)
+		}
+		for i := range src {
+			if p := *m.Transf(&src[i]); dst[i] != p {
+				t.Errorf("expected '%v' but got '%v'", p, dst[i])
+				break
+			}
+		}
+	}
+}
+
+func TestTransfPoints(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	src := make([]Vec3, 5000)
+	for i, v := range randVecs(r, len(src)) {
+		src[i] = Vec3{v[0], v[1], v[2]}
+	}
+	for _, workers := range []int{-1, 0, 1, 3, 8} {
+		dst := make([]Vec3, len(src))
+		if workers < 0 {
+			m.TransfPoints(dst, src)
+		} else {
+			m.ParTransfPoints(dst, src, workers)
+		}
+		for i, v := range src {
+			p := *m.Transf(NewVec4(v[0], v[1], v[2]))
+			p.Norm()
+			if q := (Vec3{p[0], p[1], p[2]}); dst[i] != q {
+				t.Errorf("expected '%v' but got '%v'", q, dst[i])
+				break
+			}
+		}
+	}
+}
+
+// benchVecs is the number of vectors transformed per benchmark iteration.
+const benchVecs = 100000
+
+func BenchmarkTransf(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	src := randVecs(r, benchVecs)
+	dst := make([]Vec4, len(src))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range src {
+			dst[j] = *m.Transf(&src[j])
+		}
+	}
+}
+
+func BenchmarkTransfAll(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	src := randVecs(r, benchVecs)
+	dst := make([]Vec4, len(src))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.TransfAll(dst, src)
+	}
+}
+
+func benchParTransfAll(b *testing.B, workers int) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	src := randVecs(r, benchVecs)
+	dst := make([]Vec4, len(src))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ParTransfAll(dst, src, workers)
+	}
+}
+
+func BenchmarkParTransfAll2(b *testing.B) { benchParTransfAll(b, 2) }
+func BenchmarkParTransfAll4(b *testing.B) { benchParTransfAll(b, 4) }
+func BenchmarkParTransfAll8(b *testing.B) { benchParTransfAll(b, 8) }
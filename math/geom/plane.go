@@ -0,0 +1,32 @@
+package geom
+
+// Plane is a plane in 3D space given by its unit normal and the distance D
+// such that Dot(Normal, p) + D = 0 for all points p on the plane.
+type Plane struct {
+	Normal Vec3
+	D      float64
+}
+
+// NewPlane returns a new plane through the 3 points. Seen from the side the
+// normal points to, the points are in counter-clockwise order.
+func NewPlane(p0, p1, p2 *Vec3) *Plane {
+	e1 := *p1
+	e1.Sub(p0)
+	e2 := *p2
+	e2.Sub(p0)
+	n := Cross(&e1, &e2)
+	n.Norm()
+	return &Plane{*n, -Dot(n, p0)}
+}
+
+// Dist returns the signed distance of point v from the plane, positive on the
+// side the normal points to.
+func (p *Plane) Dist(v *Vec3) float64 {
+	return Dot(&p.Normal, v) + p.D
+}
+
+// Flip turns the plane's normal to the other side.
+func (p *Plane) Flip() {
+	p.Normal.Neg()
+	p.D = -p.D
+}
@@ -0,0 +1,20 @@
+package geom
+
+import (
+	"testing"
+)
+
+func TestPlane(t *testing.T) {
+	p := NewPlane(&Vec3{0, 0, 1}, &Vec3{1, 0, 1}, &Vec3{0, 1, 1})
+	r := Plane{Vec3{0, 0, 1}, -1}
+	if *p != r {
+		t.Errorf("expected '%v' but got '%v'", r, *p)
+	}
+	if d := p.Dist(&Vec3{5, 5, 3}); d != 2 {
+		t.Errorf("expected '%v' but got '%v'", 2, d)
+	}
+	p.Flip()
+	if d := p.Dist(&Vec3{5, 5, 3}); d != -2 {
+		t.Errorf("expected '%v' but got '%v'", -2, d)
+	}
+}
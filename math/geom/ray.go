@@ -0,0 +1,93 @@
+package geom
+
+import (
+	"math"
+)
+
+// Ray is a half-line starting at an origin and going in a direction.
+type Ray struct {
+
+	// Orig is the point where the ray starts.
+	Orig Vec3
+
+	// Dir is the direction of the ray. With unit length distances along the
+	// ray are in the same units as the coordinates.
+	Dir Vec3
+}
+
+// At returns the point at distance t along the ray, measured in lengths of
+// its direction.
+func (r *Ray) At(t float64) *Vec3 {
+	p := r.Dir
+	p.Scale(t)
+	p.Add(&r.Orig)
+	return &p
+}
+
+// epsilon is the tolerance below which a ray is considered parallel to a
+// triangle.
+const epsilon = 1e-12
+
+// IntersectTri returns where the ray hits the triangle p0, p1, p2 with the
+// Möller–Trumbore algorithm. t is the distance along the ray, u and v are the
+// barycentric coordinates of the hit for p1 and p2, the one for p0 is 1-u-v.
+// Both sides of the triangle are hit. It returns false if the ray misses the
+// triangle, is parallel to it or hits it behind the origin.
+func (r *Ray) IntersectTri(p0, p1, p2 *Vec3) (t, u, v float64, ok bool) {
+	e1 := *p1
+	e1.Sub(p0)
+	e2 := *p2
+	e2.Sub(p0)
+	p := Cross(&r.Dir, &e2)
+	det := Dot(&e1, p)
+	if math.Abs(det) < epsilon {
+		return 0, 0, 0, false
+	}
+	s := r.Orig
+	s.Sub(p0)
+	u = Dot(&s, p) / det
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+	q := Cross(&s, &e1)
+	v = Dot(&r.Dir, q) / det
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+	t = Dot(&e2, q) / det
+	if t < 0 {
+		return 0, 0, 0, false
+	}
+	return t, u, v, true
+}
+
+// IntersectBox returns the distances along the ray where it enters and leaves
+// the axis-aligned box with the given minimum and maximum corner (slab
+// method). If the origin is inside the box the entry is 0. It returns false if
+// the ray misses the box, the box is behind the origin or empty.
+func (r *Ray) IntersectBox(min, max *Vec3) (float64, float64, bool) {
+	tmin := 0.0
+	tmax := math.Inf(1)
+	for k := 0; k < 3; k++ {
+		if min[k] > max[k] {
+			return 0, 0, false
+		}
+		if r.Dir[k] == 0 {
+			if r.Orig[k] < min[k] || r.Orig[k] > max[k] {
+				return 0, 0, false
+			}
+			continue
+		}
+		t0 := (min[k] - r.Orig[k]) / r.Dir[k]
+		t1 := (max[k] - r.Orig[k]) / r.Dir[k]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		tmin = math.Max(tmin, t0)
+		tmax = math.Min(tmax, t1)
+		if tmin > tmax {
+			return 0, 0, false
+		}
+	}
+	return tmin, tmax, true
+}
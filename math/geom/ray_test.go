@@ -0,0 +1,75 @@
+package geom
+
+import (
+	"testing"
+)
+
+func TestRayAt(t *testing.T) {
+	r := Ray{Vec3{1, 2, 3}, Vec3{0, 0, -1}}
+	p := *r.At(2)
+	q := Vec3{1, 2, 1}
+	if p != q {
+		t.Errorf("expected '%v' but got '%v'", q, p)
+	}
+}
+
+var tritests = []struct {
+	ray     Ray
+	ok      bool
+	t, u, v float64
+}{
+	// Straight through p0, p1 and the center of the triangle
+	{Ray{Vec3{0, 0, 1}, Vec3{0, 0, -1}}, true, 1, 0, 0},
+	{Ray{Vec3{2, 0, 1}, Vec3{0, 0, -1}}, true, 1, 1, 0},
+	{Ray{Vec3{0.5, 0.5, 2}, Vec3{0, 0, -1}}, true, 2, 0.25, 0.25},
+	// From behind
+	{Ray{Vec3{0.5, 0.5, -1}, Vec3{0, 0, 1}}, true, 1, 0.25, 0.25},
+	// Beside, parallel and with the triangle behind
+	{Ray{Vec3{2, 2, 1}, Vec3{0, 0, -1}}, false, 0, 0, 0},
+	{Ray{Vec3{0, 0, 1}, Vec3{1, 0, 0}}, false, 0, 0, 0},
+	{Ray{Vec3{0.5, 0.5, 1}, Vec3{0, 0, 1}}, false, 0, 0, 0},
+}
+
+func TestIntersectTri(t *testing.T) {
+	p0, p1, p2 := Vec3{0, 0, 0}, Vec3{2, 0, 0}, Vec3{0, 2, 0}
+	for _, test := range tritests {
+		d, u, v, ok := test.ray.IntersectTri(&p0, &p1, &p2)
+		if ok != test.ok || d != test.t || u != test.u || v != test.v {
+			t.Errorf("expected '%v' but got '%v'",
+				[]interface{}{test.ok, test.t, test.u, test.v},
+				[]interface{}{ok, d, u, v})
+		}
+	}
+}
+
+var boxtests = []struct {
+	ray        Ray
+	ok         bool
+	tmin, tmax float64
+}{
+	{Ray{Vec3{0, 0, 5}, Vec3{0, 0, -1}}, true, 4, 6},
+	{Ray{Vec3{0, 0, 0}, Vec3{1, 0, 0}}, true, 0, 1},
+	{Ray{Vec3{-3, -3, 0}, Vec3{1, 1, 0}}, true, 2, 4},
+	{Ray{Vec3{0, 2, 5}, Vec3{0, 0, -1}}, false, 0, 0},
+	{Ray{Vec3{0, 0, 5}, Vec3{0, 0, 1}}, false, 0, 0},
+}
+
+func TestIntersectBox(t *testing.T) {
+	min, max := Vec3{-1, -1, -1}, Vec3{1, 1, 1}
+	for _, test := range boxtests {
+		tmin, tmax, ok := test.ray.IntersectBox(&min, &max)
+		if ok != test.ok || tmin != test.tmin || tmax != test.tmax {
+			t.Errorf("expected '%v' but got '%v'",
+				[]interface{}{test.ok, test.tmin, test.tmax},
+				[]interface{}{ok, tmin, tmax})
+		}
+	}
+}
+
+func TestIntersectEmptyBox(t *testing.T) {
+	r := Ray{Vec3{0, 0, 5}, Vec3{0, 0, -1}}
+	b := EmptyBox()
+	if _, _, ok := r.IntersectBox(&b.Min, &b.Max); ok {
+		t.Errorf("expected empty box to be missed")
+	}
+}
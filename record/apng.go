@@ -0,0 +1,164 @@
+package record
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"github.com/amsibamsi/three/image"
+	"hash/crc32"
+	"io"
+)
+
+// Apng records an animated PNG with 8 bit RGBA pixels. Viewers that don't know
+// about animation show the first frame. Frames are compressed when added and
+// kept in memory since the number of frames has to be written before the first
+// one. The file is written on Close.
+type Apng struct {
+
+	// LoopCount is the number of times the animation is played, 0 loops
+	// forever.
+	LoopCount int
+
+	// Output
+	w io.Writer
+
+	// Frames per second
+	fps int
+
+	// Size of the first frame
+	width, height int
+
+	// Compressed image data of each frame
+	frames [][]byte
+}
+
+// NewApng returns a new APNG recorder writing to w with the given frames per
+// second that loops forever.
+func NewApng(w io.Writer, fps int) *Apng {
+	return &Apng{w: w, fps: fps}
+}
+
+// Add compresses the image and appends it as a new frame. Colors are stored
+// with straight alpha as PNG requires.
+func (a *Apng) Add(img *image.Image) error {
+	w, h := size(img)
+	if len(a.frames) == 0 {
+		a.width = w
+		a.height = h
+	} else if w != a.width || h != a.height {
+		return errSize
+	}
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	r := &img.Rgba
+	row := make([]byte, 1+4*w)
+	for y := 0; y < h; y++ {
+		// Filter type 0 (none) at the start of each row
+		o := r.PixOffset(r.Rect.Min.X, r.Rect.Min.Y+y)
+		copy(row[1:], r.Pix[o:o+4*w])
+		unpremultiply(row[1:])
+		if _, err := z.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	a.frames = append(a.frames, buf.Bytes())
+	return nil
+}
+
+// unpremultiply converts RGBA pixels with premultiplied alpha to straight
+// alpha in place.
+func unpremultiply(pix []byte) {
+	for i := 0; i < len(pix); i += 4 {
+		a := uint32(pix[i+3])
+		if a == 255 {
+			continue
+		}
+		for k := 0; k < 3; k++ {
+			c := uint32(0)
+			if a > 0 {
+				c = (uint32(pix[i+k])*255 + a/2) / a
+			}
+			if c > 255 {
+				c = 255
+			}
+			pix[i+k] = uint8(c)
+		}
+	}
+}
+
+// chunk writes a PNG chunk with the given type and data.
+func chunk(w io.Writer, typ string, data []byte) error {
+	var head [8]byte
+	binary.BigEndian.PutUint32(head[:4], uint32(len(data)))
+	copy(head[4:], typ)
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+	var tail [4]byte
+	binary.BigEndian.PutUint32(tail[:], crc.Sum32())
+	for _, b := range [][]byte{head[:], data, tail[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the PNG signature, header, animation control and all frames.
+// Returns an error if no frames were added, since a PNG needs at least one
+// image.
+func (a *Apng) Close() error {
+	if len(a.frames) == 0 {
+		return errNoFrames
+	}
+	be := binary.BigEndian
+	if _, err := io.WriteString(a.w, "\x89PNG\r\n\x1a\n"); err != nil {
+		return err
+	}
+	ihdr := make([]byte, 13)
+	be.PutUint32(ihdr[0:], uint32(a.width))
+	be.PutUint32(ihdr[4:], uint32(a.height))
+	ihdr[8] = 8 // Bit depth
+	ihdr[9] = 6 // Color type RGBA
+	if err := chunk(a.w, "IHDR", ihdr); err != nil {
+		return err
+	}
+	actl := make([]byte, 8)
+	be.PutUint32(actl[0:], uint32(len(a.frames)))
+	be.PutUint32(actl[4:], uint32(a.LoopCount))
+	if err := chunk(a.w, "acTL", actl); err != nil {
+		return err
+	}
+	seq := uint32(0)
+	for i, data := range a.frames {
+		fctl := make([]byte, 26)
+		be.PutUint32(fctl[0:], seq)
+		be.PutUint32(fctl[4:], uint32(a.width))
+		be.PutUint32(fctl[8:], uint32(a.height))
+		// Offsets x and y stay 0
+		be.PutUint16(fctl[20:], 1)
+		be.PutUint16(fctl[22:], uint16(a.fps))
+		// Dispose and blend ops stay 0: none and source
+		if err := chunk(a.w, "fcTL", fctl); err != nil {
+			return err
+		}
+		seq++
+		if i == 0 {
+			if err := chunk(a.w, "IDAT", data); err != nil {
+				return err
+			}
+			continue
+		}
+		fdat := make([]byte, 4+len(data))
+		be.PutUint32(fdat, seq)
+		copy(fdat[4:], data)
+		if err := chunk(a.w, "fdAT", fdat); err != nil {
+			return err
+		}
+		seq++
+	}
+	return chunk(a.w, "IEND", nil)
+}
@@ -0,0 +1,3 @@
+// Package record provides recording of frame sequences into video and animated
+// image formats.
+package record
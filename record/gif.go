@@ -0,0 +1,221 @@
+package record
+
+import (
+	"github.com/amsibamsi/three/image"
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sort"
+)
+
+// Gif records an animated GIF. Each frame gets its own palette found by median
+// cut and is optionally dithered. Frames are kept in memory and the file is
+// written on Close.
+type Gif struct {
+
+	// Colors is the maximum number of palette colors per frame, at most 256.
+	Colors int
+
+	// Dither enables Floyd-Steinberg error diffusion when mapping frames to
+	// their palette.
+	Dither bool
+
+	// LoopCount is the number of times the animation is repeated, 0 loops
+	// forever and -1 shows it only once.
+	LoopCount int
+
+	// Output
+	w io.Writer
+
+	// Delay between frames in 100ths of a second
+	delay int
+
+	// Frames recorded so far
+	anim gif.GIF
+}
+
+// NewGif returns a new GIF recorder writing to w with the given frames per
+// second, 256 colors, dithering and infinite looping. GIF delays are given in
+// 100ths of a second, so fps is rounded accordingly. An fps below 1 is taken
+// as 1.
+func NewGif(w io.Writer, fps int) *Gif {
+	if fps < 1 {
+		fps = 1
+	}
+	d := 100 / fps
+	if d < 1 {
+		d = 1
+	}
+	return &Gif{Colors: 256, Dither: true, w: w, delay: d}
+}
+
+// Add quantizes the image to a palette and appends it as a new frame.
+func (g *Gif) Add(img *image.Image) error {
+	if len(g.anim.Image) > 0 {
+		w, h := size(img)
+		b := g.anim.Image[0].Bounds()
+		if w != b.Dx() || h != b.Dy() {
+			return errSize
+		}
+	}
+	src := &img.Rgba
+	b := src.Bounds()
+	p := stdimage.NewPaletted(
+		stdimage.Rect(0, 0, b.Dx(), b.Dy()),
+		Quantize(src, g.Colors),
+	)
+	if g.Dither {
+		draw.FloydSteinberg.Draw(p, p.Rect, src, b.Min)
+	} else {
+		draw.Draw(p, p.Rect, src, b.Min, draw.Src)
+	}
+	g.anim.Image = append(g.anim.Image, p)
+	g.anim.Delay = append(g.anim.Delay, g.delay)
+	return nil
+}
+
+// Close encodes all frames and writes the GIF.
+func (g *Gif) Close() error {
+	g.anim.LoopCount = g.LoopCount
+	return gif.EncodeAll(g.w, &g.anim)
+}
+
+// colorCount is a distinct color of an image and how often it occurs.
+type colorCount struct {
+	c [3]uint8
+	n int
+}
+
+// colorBox is a set of colors for median cut.
+type colorBox []colorCount
+
+// byChannel sorts colors by one channel, ties are broken by the whole color to
+// keep the result deterministic.
+type byChannel struct {
+	colorBox
+	ch int
+}
+
+func (b byChannel) Len() int      { return len(b.colorBox) }
+func (b byChannel) Swap(i, j int) { b.colorBox[i], b.colorBox[j] = b.colorBox[j], b.colorBox[i] }
+func (b byChannel) Less(i, j int) bool {
+	ci := b.colorBox[i].c
+	cj := b.colorBox[j].c
+	for k := 0; k < 3; k++ {
+		ch := (b.ch + k) % 3
+		if ci[ch] != cj[ch] {
+			return ci[ch] < cj[ch]
+		}
+	}
+	return false
+}
+
+// widest returns the channel with the widest range of values in the box and
+// the range.
+func (b colorBox) widest() (int, int) {
+	ch, rng := 0, -1
+	for k := 0; k < 3; k++ {
+		lo, hi := 255, 0
+		for _, cc := range b {
+			v := int(cc.c[k])
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		if hi-lo > rng {
+			ch, rng = k, hi-lo
+		}
+	}
+	return ch, rng
+}
+
+// mean returns the average color of the box weighted by occurrence.
+func (b colorBox) mean() color.Color {
+	var sum [3]int
+	n := 0
+	for _, cc := range b {
+		for k := 0; k < 3; k++ {
+			sum[k] += int(cc.c[k]) * cc.n
+		}
+		n += cc.n
+	}
+	return color.RGBA{
+		uint8((sum[0] + n/2) / n),
+		uint8((sum[1] + n/2) / n),
+		uint8((sum[2] + n/2) / n),
+		255,
+	}
+}
+
+// Quantize returns a palette with at most n colors that represents the colors
+// of the image. It uses median cut: Starting with one box of all colors, the
+// box with the widest channel range is repeatedly split at the median of that
+// channel until there are n boxes. The palette colors are the average colors of
+// the boxes. The alpha channel is ignored.
+func Quantize(img *stdimage.RGBA, n int) color.Palette {
+	if n > 256 {
+		n = 256
+	}
+	if n < 1 {
+		n = 1
+	}
+	counts := make(map[[3]uint8]int)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := img.PixOffset(x, y)
+			counts[[3]uint8{img.Pix[o], img.Pix[o+1], img.Pix[o+2]}]++
+		}
+	}
+	all := make(colorBox, 0, len(counts))
+	for c, k := range counts {
+		all = append(all, colorCount{c, k})
+	}
+	sort.Sort(byChannel{all, 0})
+	boxes := []colorBox{all}
+	for len(boxes) < n {
+		// Find the box with the widest range that can still be split
+		best, bestCh, bestRng := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			ch, rng := box.widest()
+			if rng > bestRng {
+				best, bestCh, bestRng = i, ch, rng
+			}
+		}
+		if best < 0 {
+			break
+		}
+		box := boxes[best]
+		sort.Sort(byChannel{box, bestCh})
+		total := 0
+		for _, cc := range box {
+			total += cc.n
+		}
+		// Split at the weighted median, but keep both halves non-empty
+		m, acc := 1, box[0].n
+		for m < len(box)-1 && acc < total/2 {
+			acc += box[m].n
+			m++
+		}
+		boxes[best] = box[:m]
+		boxes = append(boxes, box[m:])
+	}
+	p := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		if len(box) > 0 {
+			p = append(p, box.mean())
+		}
+	}
+	if len(p) == 0 {
+		p = append(p, color.RGBA{0, 0, 0, 255})
+	}
+	return p
+}
@@ -0,0 +1,86 @@
+package record
+
+import (
+	"errors"
+	"github.com/amsibamsi/three/image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Recorder records a sequence of frames. All frames must have the same size.
+// Close must be called after the last frame to finish the recording, it does
+// not close the underlying writer.
+type Recorder interface {
+	Add(img *image.Image) error
+	Close() error
+}
+
+// Source provides frames, e.g. a *window.Window.
+type Source interface {
+	Image() *image.Image
+}
+
+// Capture adds the current image of the source as a new frame to the recorder.
+func Capture(r Recorder, s Source) error {
+	return r.Add(s.Image())
+}
+
+// errSize is returned when a frame's size doesn't match the first frame.
+var errSize = errors.New("Frame size differs from first frame")
+
+// errNoFrames is returned when closing a format that can't be empty.
+var errNoFrames = errors.New("No frames recorded")
+
+// errFps is returned when the frame rate is not positive.
+var errFps = errors.New("Frames per second must be > 0")
+
+// size returns the width and height of an image.
+func size(img *image.Image) (int, int) {
+	b := img.Rgba.Bounds()
+	return b.Dx(), b.Dy()
+}
+
+// fileRecorder is a recorder that also closes the file it writes to.
+type fileRecorder struct {
+	Recorder
+	f *os.File
+}
+
+// Close finishes the recording and closes the file.
+func (r *fileRecorder) Close() error {
+	err := r.Recorder.Close()
+	cerr := r.f.Close()
+	if err != nil {
+		return err
+	}
+	return cerr
+}
+
+// Create creates a file with the given name and returns a recorder writing to
+// it with the given frames per second. The format is chosen by the file
+// extension: ".y4m" for Y4M video, ".gif" for animated GIF and ".png" or ".apng"
+// for animated PNG. Closing the recorder also closes the file. Returns an
+// error if fps is not > 0.
+func Create(name string, fps int) (Recorder, error) {
+	if fps < 1 {
+		return nil, errFps
+	}
+	var newRec func(io.Writer, int) Recorder
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".y4m":
+		newRec = func(w io.Writer, fps int) Recorder { return NewY4m(w, fps) }
+	case ".gif":
+		newRec = func(w io.Writer, fps int) Recorder { return NewGif(w, fps) }
+	case ".png", ".apng":
+		newRec = func(w io.Writer, fps int) Recorder { return NewApng(w, fps) }
+	default:
+		return nil, errors.New("Unknown recording format: " + name)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &fileRecorder{newRec(f, fps), f}, nil
+}
@@ -0,0 +1,182 @@
+package record
+
+import (
+	"bytes"
+	"github.com/amsibamsi/three/image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+// frames returns n images of size 4x3 with a dot moving to the right.
+func frames(n int) []*image.Image {
+	f := make([]*image.Image, n)
+	for i := range f {
+		f[i] = image.NewImage(4, 3)
+		f[i].Rgba.Set(i%4, 1, color.RGBA{255, 0, 0, 255})
+	}
+	return f
+}
+
+func TestY4m(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewY4m(&buf, 25)
+	for _, f := range frames(2) {
+		if err := r.Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Close()
+	head := "YUV4MPEG2 W4 H3 F25:1 Ip A1:1 C444\n"
+	if !bytes.HasPrefix(buf.Bytes(), []byte(head)) {
+		t.Errorf("expected header '%v' but got '%v'", head, buf.String()[:len(head)])
+	}
+	l := len(head) + 2*(len("FRAME\n")+3*4*3)
+	if buf.Len() != l {
+		t.Errorf("expected '%v' bytes but got '%v'", l, buf.Len())
+	}
+	// Black is Y=16, Cb=Cr=128 in limited range
+	frame := buf.Bytes()[len(head)+len("FRAME\n"):]
+	if frame[0] != 16 || frame[12] != 128 || frame[24] != 128 {
+		t.Errorf("expected black but got '%v'", frame[:36])
+	}
+}
+
+func TestY4mSize(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewY4m(&buf, 25)
+	r.Add(image.NewImage(4, 3))
+	err := r.Add(image.NewImage(3, 3))
+	if err != errSize {
+		t.Errorf("expected '%v' but got '%v'", errSize, err)
+	}
+}
+
+func TestY4mFps(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewY4m(&buf, 0)
+	if err := r.Add(image.NewImage(4, 3)); err != errFps {
+		t.Errorf("expected '%v' but got '%v'", errFps, err)
+	}
+	if _, err := Create("test.y4m", -1); err != errFps {
+		t.Errorf("expected '%v' but got '%v'", errFps, err)
+	}
+}
+
+func TestGif(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewGif(&buf, 10)
+	for _, f := range frames(3) {
+		if err := r.Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 3 {
+		t.Errorf("expected '%v' frames but got '%v'", 3, len(g.Image))
+	}
+	if g.Delay[0] != 10 {
+		t.Errorf("expected delay '%v' but got '%v'", 10, g.Delay[0])
+	}
+	c := color.RGBAModel.Convert(g.Image[2].At(2, 1))
+	r1 := color.RGBA{255, 0, 0, 255}
+	if c != r1 {
+		t.Errorf("expected '%v' but got '%v'", r1, c)
+	}
+}
+
+func TestGifDelay(t *testing.T) {
+	for _, fps := range []int{0, -5, 1} {
+		if d := NewGif(nil, fps).delay; d != 100 {
+			t.Errorf("expected '%v' but got '%v'", 100, d)
+		}
+	}
+}
+
+var quantizetests = []struct {
+	n, colors int
+}{
+	{1, 1},
+	{2, 2},
+	{256, 3},
+}
+
+func TestQuantize(t *testing.T) {
+	img := image.NewImage(3, 1)
+	img.Rgba.Set(1, 0, color.RGBA{255, 0, 0, 255})
+	img.Rgba.Set(2, 0, color.RGBA{0, 0, 255, 255})
+	for _, test := range quantizetests {
+		p := Quantize(&img.Rgba, test.n)
+		if len(p) != test.colors {
+			t.Errorf("expected '%v' colors but got '%v'", test.colors, len(p))
+		}
+	}
+}
+
+func TestApng(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewApng(&buf, 30)
+	for _, f := range frames(3) {
+		if err := r.Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("fcTL")); n != 3 {
+		t.Errorf("expected '%v' frame controls but got '%v'", 3, n)
+	}
+	// Decoders without APNG support see the first frame
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := color.RGBAModel.Convert(img.At(0, 1))
+	r1 := color.RGBA{255, 0, 0, 255}
+	if c != r1 {
+		t.Errorf("expected '%v' but got '%v'", r1, c)
+	}
+}
+
+func TestApngTranslucent(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewApng(&buf, 30)
+	img := image.NewImage(2, 1)
+	// Premultiplied, straight alpha is twice as bright
+	img.Rgba.Set(0, 0, color.RGBA{100, 50, 0, 128})
+	img.Rgba.Set(1, 0, color.RGBA{})
+	r.Add(img)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	should := [2]color.Color{color.NRGBA{199, 100, 0, 128}, color.NRGBA{}}
+	is := [2]color.Color{
+		color.NRGBAModel.Convert(dec.At(0, 0)),
+		color.NRGBAModel.Convert(dec.At(1, 0)),
+	}
+	if is != should {
+		t.Errorf("expected '%v' but got '%v'", should, is)
+	}
+}
+
+func TestApngEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewApng(&buf, 30).Close(); err != errNoFrames {
+		t.Errorf("expected '%v' but got '%v'", errNoFrames, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written but got '%v' bytes", buf.Len())
+	}
+}
@@ -0,0 +1,75 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/amsibamsi/three/image"
+	"io"
+)
+
+// Y4m records uncompressed YUV4MPEG2 video. Colors are converted to Y'CbCr
+// with BT.601 coefficients in limited range and stored without chroma
+// subsampling (4:4:4). Frames are written immediately.
+type Y4m struct {
+
+	// Buffered output
+	w *bufio.Writer
+
+	// Frames per second
+	fps int
+
+	// Size of the first frame, 0 until then
+	width, height int
+
+	// Planes of the current frame: Y, Cb and Cr
+	planes []byte
+}
+
+// NewY4m returns a new Y4M recorder writing to w with the given frames per
+// second. Frames can only be added if fps is > 0.
+func NewY4m(w io.Writer, fps int) *Y4m {
+	return &Y4m{w: bufio.NewWriter(w), fps: fps}
+}
+
+// Add writes a frame. The stream header is written with the first frame.
+func (y *Y4m) Add(img *image.Image) error {
+	if y.fps < 1 {
+		return errFps
+	}
+	w, h := size(img)
+	if y.width == 0 && y.height == 0 {
+		y.width = w
+		y.height = h
+		y.planes = make([]byte, 3*w*h)
+		_, err := fmt.Fprintf(y.w, "YUV4MPEG2 W%d H%d F%d:1 Ip A1:1 C444\n", w, h, y.fps)
+		if err != nil {
+			return err
+		}
+	} else if w != y.width || h != y.height {
+		return errSize
+	}
+	n := w * h
+	r := &img.Rgba
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			o := r.PixOffset(px+r.Rect.Min.X, py+r.Rect.Min.Y)
+			cr := int(r.Pix[o])
+			cg := int(r.Pix[o+1])
+			cb := int(r.Pix[o+2])
+			i := py*w + px
+			y.planes[i] = byte(((66*cr + 129*cg + 25*cb + 128) >> 8) + 16)
+			y.planes[n+i] = byte(((-38*cr - 74*cg + 112*cb + 128) >> 8) + 128)
+			y.planes[2*n+i] = byte(((112*cr - 94*cg - 18*cb + 128) >> 8) + 128)
+		}
+	}
+	if _, err := y.w.WriteString("FRAME\n"); err != nil {
+		return err
+	}
+	_, err := y.w.Write(y.planes)
+	return err
+}
+
+// Close flushes any buffered data.
+func (y *Y4m) Close() error {
+	return y.w.Flush()
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+)
+
+// Accel speeds up queries on a scene with a bounding volume hierarchy over its
+// objects and one over the triangles of each mesh. Meshes are expected to
+// stay as they are. If objects move, Refit updates the hierarchy over them.
+// After adding or removing objects a new Accel is needed.
+type Accel struct {
+
+	// Objects with vertices, indexed like the boxes of objs
+	objects []*Object
+
+	// Inverse transformation of each object
+	invs []geom.Mat4
+
+	// Hierarchies over the objects and over the triangles of each mesh
+	objs   *Bvh
+	meshes map[*Mesh]*Bvh
+}
+
+// NewAccel returns a new acceleration structure for scene s.
+func NewAccel(s *Scene) *Accel {
+	a := &Accel{meshes: make(map[*Mesh]*Bvh)}
+	for _, o := range s.Objects {
+		if len(o.Mesh.Verts) == 0 {
+			continue
+		}
+		a.objects = append(a.objects, o)
+		if _, ok := a.meshes[o.Mesh]; ok {
+			continue
+		}
+		boxes := make([]geom.Box, len(o.Mesh.Tris))
+		for i := range o.Mesh.Tris {
+			p0, p1, p2 := o.Mesh.Tri(i)
+			b := geom.EmptyBox()
+			b.AddPoint(p0)
+			b.AddPoint(p1)
+			b.AddPoint(p2)
+			boxes[i] = *b
+		}
+		a.meshes[o.Mesh] = NewBvh(boxes)
+	}
+	a.invs = make([]geom.Mat4, len(a.objects))
+	a.objs = NewBvh(a.boxes())
+	return a
+}
+
+// boxes returns the world bounds of the objects and updates their inverse
+// transformations.
+func (a *Accel) boxes() []geom.Box {
+	boxes := make([]geom.Box, len(a.objects))
+	for i, o := range a.objects {
+		b := geom.EmptyBox()
+		for _, c := range o.corners() {
+			b.AddPoint(&c)
+		}
+		boxes[i] = *b
+		inv, ok := o.Transf.Inv()
+		if !ok {
+			// Flattened objects can't be hit
+			inv = geom.ZeroMat()
+		}
+		a.invs[i] = *inv
+	}
+	return boxes
+}
+
+// Refit updates the hierarchy over the objects after their transformations
+// changed.
+func (a *Accel) Refit() {
+	a.objs.Refit(a.boxes())
+}
+
+// Pick returns the nearest hit of ray r with any triangle in the scene like
+// Scene.Pick, or nil if nothing is hit.
+func (a *Accel) Pick(r *geom.Ray) *Hit {
+	var hit *Hit
+	a.objs.Ray(r, math.Inf(1), func(i int, tmax float64) float64 {
+		o := a.objects[i]
+		// Ray in object coordinates, distances stay the same with an affine
+		// transformation since the direction isn't normalized
+		inv := &a.invs[i]
+		orig := inv.Transf(geom.NewVec4(r.Orig[0], r.Orig[1], r.Orig[2]))
+		orig.Norm()
+		dir := inv.Transf(&geom.Vec4{r.Dir[0], r.Dir[1], r.Dir[2], 0})
+		or := &geom.Ray{
+			Orig: geom.Vec3{orig[0], orig[1], orig[2]},
+			Dir:  geom.Vec3{dir[0], dir[1], dir[2]},
+		}
+		return a.meshes[o.Mesh].Ray(or, tmax, func(j int, tmax float64) float64 {
+			p0, p1, p2 := o.Mesh.Tri(j)
+			t, u, v, ok := or.IntersectTri(p0, p1, p2)
+			if !ok || t >= tmax {
+				return tmax
+			}
+			hit = &Hit{Object: o, Tri: j, Dist: t, Pos: *r.At(t), U: u, V: v}
+			return t
+		})
+	})
+	return hit
+}
+
+// Overlapping returns the objects whose bounds in world coordinates overlap
+// box b, e.g. as candidates for collisions.
+func (a *Accel) Overlapping(b *geom.Box) []*Object {
+	var os []*Object
+	a.objs.Overlap(b, func(i int) {
+		os = append(os, a.objects[i])
+	})
+	return os
+}
+
+// Visible returns the objects whose bounds are not completely outside the
+// frustum of camera c. Objects returned may still be hidden behind others.
+func (a *Accel) Visible(c *Camera) []*Object {
+	var os []*Object
+	a.objs.Cull(c.Planes(), func(i int) {
+		os = append(os, a.objects[i])
+	})
+	return os
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math/rand"
+	"testing"
+)
+
+// cubes returns a scene with n randomly placed, rotated and scaled cubes
+// sharing one mesh within [-10,10].
+func cubes(r *rand.Rand, n int) *Scene {
+	s := NewScene()
+	m := NewCube()
+	for i := 0; i < n; i++ {
+		o := NewObject(m, nil)
+		o.Transf = *TranslTransf(&geom.Vec3{
+			r.Float64()*18 - 9, r.Float64()*18 - 9, r.Float64()*18 - 9})
+		o.Transf.Mul(RotTransf(&geom.Vec3{r.Float64(), r.Float64(), 1}, r.Float64()*6))
+		o.Transf.Mul(ScaleTransf(&geom.Vec3{r.Float64() + 0.2, 1, 0.5}))
+		s.Add(o)
+	}
+	return s
+}
+
+// samePick returns true if both hits are nil or hit the same triangle at the
+// same distance.
+func samePick(a, b *Hit) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Object == b.Object && a.Tri == b.Tri && nearVec(&a.Pos, &b.Pos)
+}
+
+func TestAccelPick(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	s := cubes(r, 100)
+	a := NewAccel(s)
+	hits := 0
+	for n := 0; n < 200; n++ {
+		ray := randRay(r)
+		h := a.Pick(ray)
+		if sh := s.Pick(ray); !samePick(h, sh) {
+			t.Errorf("expected '%v' but got '%v'", sh, h)
+		}
+		if h != nil {
+			hits++
+		}
+	}
+	if hits == 0 {
+		t.Errorf("expected some hits")
+	}
+	// Same results after moving the objects
+	for _, o := range s.Objects {
+		o.Transf.Mul(TranslTransf(&geom.Vec3{1, -1, 0.5}))
+	}
+	a.Refit()
+	for n := 0; n < 200; n++ {
+		ray := randRay(r)
+		if h, sh := a.Pick(ray), s.Pick(ray); !samePick(h, sh) {
+			t.Errorf("expected '%v' but got '%v'", sh, h)
+		}
+	}
+}
+
+func TestAccelQueries(t *testing.T) {
+	s := NewScene()
+	front := NewObject(NewCube(), nil)
+	front.Transf = *TranslTransf(&geom.Vec3{0, 0, -3})
+	behind := NewObject(NewCube(), nil)
+	behind.Transf = *TranslTransf(&geom.Vec3{0, 0, 3})
+	s.Add(front, behind, NewObject(&Mesh{}, nil))
+	a := NewAccel(s)
+	if os := a.Visible(NewDefCam()); len(os) != 1 || os[0] != front {
+		t.Errorf("expected '%v' but got '%v'", []*Object{front}, os)
+	}
+	b := &geom.Box{Min: geom.Vec3{-1, -1, 2}, Max: geom.Vec3{1, 1, 2.6}}
+	if os := a.Overlapping(b); len(os) != 1 || os[0] != behind {
+		t.Errorf("expected '%v' but got '%v'", []*Object{behind}, os)
+	}
+}
@@ -0,0 +1,304 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+)
+
+const (
+	// Number of bins along an axis when searching the best split
+	bvhBins = 12
+
+	// Nodes with at most this many items are not split
+	bvhMinLeaf = 2
+
+	// Nodes with more items are always split if possible
+	bvhMaxLeaf = 16
+)
+
+// bvhNode is a node of a bounding volume hierarchy. A leaf holds count items
+// starting at start, an inner node has count 0 and its two children at start
+// and start+1.
+type bvhNode struct {
+	box          geom.Box
+	start, count int
+}
+
+// Bvh is a bounding volume hierarchy over items given by their bounding boxes,
+// e.g. the triangles of a mesh or the objects of a scene. It is a binary tree
+// of boxes where each node's box contains the boxes of its children, so
+// queries can skip whole subtrees. Items are identified by their index in the
+// boxes given when building it.
+type Bvh struct {
+	nodes []bvhNode
+
+	// Item indices, ordered so that each leaf's items are consecutive
+	items []int
+
+	// Box of each item
+	boxes []geom.Box
+}
+
+// NewBvh returns a new bounding volume hierarchy over items with the given
+// non-empty boxes. Nodes are split where the surface area heuristic (SAH)
+// estimates the lowest cost for ray queries, choosing among bvhBins evenly
+// spaced planes along each axis (binning).
+func NewBvh(boxes []geom.Box) *Bvh {
+	b := &Bvh{
+		items: make([]int, len(boxes)),
+		boxes: make([]geom.Box, len(boxes)),
+	}
+	copy(b.boxes, boxes)
+	for i := range b.items {
+		b.items[i] = i
+	}
+	if len(boxes) > 0 {
+		b.nodes = append(b.nodes, bvhNode{})
+		b.split(0, 0, len(boxes))
+	}
+	return b
+}
+
+// split makes node n the node for items start to end and splits it
+// recursively.
+func (b *Bvh) split(n, start, end int) {
+	box := geom.EmptyBox()
+	cbox := geom.EmptyBox()
+	for _, i := range b.items[start:end] {
+		box.AddBox(&b.boxes[i])
+		cbox.AddPoint(b.boxes[i].Center())
+	}
+	b.nodes[n] = bvhNode{*box, start, end - start}
+	count := end - start
+	if count <= bvhMinLeaf {
+		return
+	}
+	// Cost relative to intersecting one item, traversing a node costs 1
+	area := box.Area()
+	if area == 0 {
+		area = 1
+	}
+	best := math.Inf(1)
+	axis := -1
+	split := 0
+	bin := func(i, k int) int {
+		c := b.boxes[i].Center()
+		j := int(bvhBins * (c[k] - cbox.Min[k]) / (cbox.Max[k] - cbox.Min[k]))
+		if j >= bvhBins {
+			j = bvhBins - 1
+		}
+		return j
+	}
+	for k := 0; k < 3; k++ {
+		if cbox.Max[k] <= cbox.Min[k] {
+			continue
+		}
+		var bins [bvhBins]geom.Box
+		var ns [bvhBins]int
+		for j := range bins {
+			bins[j] = *geom.EmptyBox()
+		}
+		for _, i := range b.items[start:end] {
+			j := bin(i, k)
+			bins[j].AddBox(&b.boxes[i])
+			ns[j]++
+		}
+		// Area and count left of each split, swept from the left
+		var left [bvhBins]float64
+		var nleft [bvhBins]int
+		l := geom.EmptyBox()
+		nl := 0
+		for j := 1; j < bvhBins; j++ {
+			l.AddBox(&bins[j-1])
+			nl += ns[j-1]
+			left[j] = l.Area()
+			nleft[j] = nl
+		}
+		r := geom.EmptyBox()
+		nr := 0
+		for j := bvhBins - 1; j > 0; j-- {
+			r.AddBox(&bins[j])
+			nr += ns[j]
+			if nleft[j] == 0 || nr == 0 {
+				continue
+			}
+			cost := 1 + (left[j]*float64(nleft[j])+r.Area()*float64(nr))/area
+			if cost < best {
+				best = cost
+				axis = k
+				split = j
+			}
+		}
+	}
+	if axis < 0 || best >= float64(count) && count <= bvhMaxLeaf {
+		return
+	}
+	// Partition items left and right of the split
+	mid := start
+	for j := start; j < end; j++ {
+		if bin(b.items[j], axis) < split {
+			b.items[j], b.items[mid] = b.items[mid], b.items[j]
+			mid++
+		}
+	}
+	c := len(b.nodes)
+	b.nodes = append(b.nodes, bvhNode{}, bvhNode{})
+	b.nodes[n].start = c
+	b.nodes[n].count = 0
+	b.split(c, start, mid)
+	b.split(c+1, mid, end)
+}
+
+// Refit updates the boxes of all items and nodes, e.g. after objects moved.
+// The tree is kept as it is, so queries stay correct but get slower the more
+// the items moved relative to each other. Boxes must have the same length as
+// when building.
+func (b *Bvh) Refit(boxes []geom.Box) {
+	copy(b.boxes, boxes)
+	// Children always come after their parent
+	for n := len(b.nodes) - 1; n >= 0; n-- {
+		node := &b.nodes[n]
+		box := geom.EmptyBox()
+		if node.count > 0 {
+			for _, i := range b.items[node.start : node.start+node.count] {
+				box.AddBox(&b.boxes[i])
+			}
+		} else {
+			box.AddBox(&b.nodes[node.start].box)
+			box.AddBox(&b.nodes[node.start+1].box)
+		}
+		node.box = *box
+	}
+}
+
+// Depth returns the number of levels of the tree, 0 if it is empty.
+func (b *Bvh) Depth() int {
+	if len(b.nodes) == 0 {
+		return 0
+	}
+	var depth func(n int) int
+	depth = func(n int) int {
+		node := &b.nodes[n]
+		if node.count > 0 {
+			return 1
+		}
+		l := depth(node.start)
+		r := depth(node.start + 1)
+		if r > l {
+			l = r
+		}
+		return l + 1
+	}
+	return depth(0)
+}
+
+// Ray calls f for every item whose box is hit by ray r nearer than tmax and
+// returns the final tmax. F is given the nearest distance so far and returns
+// the distance of its hit with the item if it is nearer, otherwise the given
+// distance. Nearer nodes are visited first and nodes behind the nearest hit
+// are skipped.
+func (b *Bvh) Ray(r *geom.Ray, tmax float64, f func(i int, tmax float64) float64) float64 {
+	if len(b.nodes) == 0 {
+		return tmax
+	}
+	type entry struct {
+		n int
+		t float64
+	}
+	t, _, ok := r.IntersectBox(&b.nodes[0].box.Min, &b.nodes[0].box.Max)
+	if !ok {
+		return tmax
+	}
+	stack := []entry{{0, t}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.t > tmax {
+			continue
+		}
+		node := &b.nodes[e.n]
+		if node.count > 0 {
+			for _, i := range b.items[node.start : node.start+node.count] {
+				box := &b.boxes[i]
+				if t, _, ok := r.IntersectBox(&box.Min, &box.Max); ok && t <= tmax {
+					tmax = f(i, tmax)
+				}
+			}
+			continue
+		}
+		var cs [2]entry
+		m := 0
+		for c := node.start; c < node.start+2; c++ {
+			box := &b.nodes[c].box
+			if t, _, ok := r.IntersectBox(&box.Min, &box.Max); ok && t <= tmax {
+				cs[m] = entry{c, t}
+				m++
+			}
+		}
+		// Push the farther child first to visit the nearer one first
+		if m == 2 && cs[0].t < cs[1].t {
+			cs[0], cs[1] = cs[1], cs[0]
+		}
+		stack = append(stack, cs[:m]...)
+	}
+	return tmax
+}
+
+// Overlap calls f for every item whose box overlaps box q.
+func (b *Bvh) Overlap(q *geom.Box, f func(i int)) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	stack := []int{0}
+	for len(stack) > 0 {
+		node := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if !node.box.Overlaps(q) {
+			continue
+		}
+		if node.count == 0 {
+			stack = append(stack, node.start, node.start+1)
+			continue
+		}
+		for _, i := range b.items[node.start : node.start+node.count] {
+			if b.boxes[i].Overlaps(q) {
+				f(i)
+			}
+		}
+	}
+}
+
+// Cull calls f for every item whose box is not completely outside of any of
+// the planes, e.g. the planes of a camera's frustum. Items near the corners of
+// the volume between the planes may be visited although they are outside of
+// it.
+func (b *Bvh) Cull(planes []geom.Plane, f func(i int)) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	outside := func(box *geom.Box) bool {
+		for k := range planes {
+			if box.Outside(&planes[k]) {
+				return true
+			}
+		}
+		return false
+	}
+	stack := []int{0}
+	for len(stack) > 0 {
+		node := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if outside(&node.box) {
+			continue
+		}
+		if node.count == 0 {
+			stack = append(stack, node.start, node.start+1)
+			continue
+		}
+		for _, i := range b.items[node.start : node.start+node.count] {
+			if !outside(&b.boxes[i]) {
+				f(i)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randBoxes returns n random boxes of size up to 1 within [-10,10].
+func randBoxes(r *rand.Rand, n int) []geom.Box {
+	boxes := make([]geom.Box, n)
+	for i := range boxes {
+		for k := 0; k < 3; k++ {
+			boxes[i].Min[k] = r.Float64()*19 - 10
+			boxes[i].Max[k] = boxes[i].Min[k] + r.Float64()
+		}
+	}
+	return boxes
+}
+
+// randRay returns a random ray starting outside of [-10,10] towards the
+// origin.
+func randRay(r *rand.Rand) *geom.Ray {
+	ray := &geom.Ray{}
+	for k := 0; k < 3; k++ {
+		ray.Orig[k] = r.Float64()*40 - 20
+		ray.Dir[k] = r.Float64()*4 - 2
+	}
+	ray.Orig[2] = 20
+	ray.Dir.Sub(&ray.Orig)
+	ray.Dir.Norm()
+	return ray
+}
+
+// nearestBox returns the index of the box with the nearest entry by ray r
+// using the hierarchy and by testing all boxes.
+func nearestBox(b *Bvh, boxes []geom.Box, r *geom.Ray) (int, int) {
+	hit := -1
+	b.Ray(r, math.Inf(1), func(i int, tmax float64) float64 {
+		t, _, _ := r.IntersectBox(&boxes[i].Min, &boxes[i].Max)
+		if t < tmax {
+			hit = i
+			return t
+		}
+		return tmax
+	})
+	all := -1
+	tmax := math.Inf(1)
+	for i := range boxes {
+		if t, _, ok := r.IntersectBox(&boxes[i].Min, &boxes[i].Max); ok && t < tmax {
+			all = i
+			tmax = t
+		}
+	}
+	return hit, all
+}
+
+func TestBvhRay(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	boxes := randBoxes(r, 1000)
+	b := NewBvh(boxes)
+	if d := b.Depth(); d < 5 || d > 30 {
+		t.Errorf("expected depth between '%v' and '%v' but got '%v'", 5, 30, d)
+	}
+	for n := 0; n < 100; n++ {
+		ray := randRay(r)
+		if hit, all := nearestBox(b, boxes, ray); hit != all {
+			t.Errorf("expected '%v' but got '%v'", all, hit)
+		}
+	}
+	// Same results after moving all boxes
+	boxes = randBoxes(r, 1000)
+	b.Refit(boxes)
+	for n := 0; n < 100; n++ {
+		ray := randRay(r)
+		if hit, all := nearestBox(b, boxes, ray); hit != all {
+			t.Errorf("expected '%v' but got '%v'", all, hit)
+		}
+	}
+}
+
+func TestBvhOverlap(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	boxes := randBoxes(r, 1000)
+	b := NewBvh(boxes)
+	q := &geom.Box{Min: geom.Vec3{-2, -2, -2}, Max: geom.Vec3{2, 3, 4}}
+	seen := make(map[int]bool)
+	b.Overlap(q, func(i int) {
+		seen[i] = true
+	})
+	for i := range boxes {
+		if o := boxes[i].Overlaps(q); o != seen[i] {
+			t.Errorf("expected '%v' but got '%v'", o, seen[i])
+		}
+	}
+}
+
+func TestBvhCull(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	boxes := randBoxes(r, 1000)
+	b := NewBvh(boxes)
+	ps := NewDefCam().Planes()
+	seen := make(map[int]bool)
+	b.Cull(ps, func(i int) {
+		seen[i] = true
+	})
+	for i := range boxes {
+		in := true
+		for k := range ps {
+			if boxes[i].Outside(&ps[k]) {
+				in = false
+			}
+		}
+		if in != seen[i] {
+			t.Errorf("expected '%v' but got '%v'", in, seen[i])
+		}
+	}
+	if len(seen) == 0 || len(seen) == len(boxes) {
+		t.Errorf("expected some boxes culled but got '%v' of '%v'", len(seen), len(boxes))
+	}
+}
+
+func TestBvhEmpty(t *testing.T) {
+	b := NewBvh(nil)
+	if d := b.Depth(); d != 0 {
+		t.Errorf("expected '%v' but got '%v'", 0, d)
+	}
+	ray := &geom.Ray{Dir: geom.Vec3{0, 0, -1}}
+	if tmax := b.Ray(ray, 5, nil); tmax != 5 {
+		t.Errorf("expected '%v' but got '%v'", 5, tmax)
+	}
+}
@@ -0,0 +1,216 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/draw"
+	"github.com/amsibamsi/three/math/geom"
+	"image/color"
+	stddraw "image/draw"
+	"math"
+)
+
+// Colors of the debug drawing
+var (
+	debugX       = color.RGBA{255, 64, 64, 255}
+	debugY       = color.RGBA{64, 255, 64, 255}
+	debugZ       = color.RGBA{64, 64, 255, 255}
+	debugGrid    = color.RGBA{96, 96, 96, 255}
+	debugFace    = color.RGBA{255, 255, 0, 255}
+	debugVert    = color.RGBA{0, 255, 255, 255}
+	debugBounds  = color.RGBA{255, 0, 255, 255}
+	debugFrustum = color.RGBA{255, 255, 255, 255}
+)
+
+// Debug draws visual aids for a scene as anti-aliased lines, usually on top of
+// the rendered scene. Everything is projected through the camera used for
+// rendering, but drawn without depth test, so parts behind objects are visible
+// too. The parts to draw can be switched on and off at any time.
+type Debug struct {
+
+	// Axes draws the world axes from the origin: x red, y green and z blue.
+	Axes bool
+
+	// Grid draws a grid on the ground plane where y = 0.
+	Grid bool
+
+	// FaceNormals draws the normal of every triangle from its center.
+	FaceNormals bool
+
+	// VertNormals draws the normal of every vertex. Vertex normals are the
+	// averaged normals of the triangles sharing the vertex.
+	VertNormals bool
+
+	// Bounds draws the bounding box of every object's mesh, transformed with
+	// the object.
+	Bounds bool
+
+	// Lights draws a cross at the position of every light in its color.
+	Lights bool
+
+	// Frustums are cameras whose frustum is drawn, e.g. to check the view of
+	// another camera from outside.
+	Frustums []*Camera
+
+	// Size is the length of the axes, the spacing of the grid and the size of
+	// light crosses in world units.
+	Size float64
+
+	// GridLines is the number of grid lines on each side of the origin.
+	GridLines int
+
+	// NormalLen is the length of normals in world units.
+	NormalLen float64
+}
+
+// NewDebug returns new debug drawing with axes and grid switched on, size 1,
+// 10 grid lines and normal length 0.2.
+func NewDebug() *Debug {
+	return &Debug{
+		Axes:      true,
+		Grid:      true,
+		Size:      1,
+		GridLines: 10,
+		NormalLen: 0.2,
+	}
+}
+
+// projector draws lines in world coordinates as seen by a camera.
+type projector struct {
+	dst  stddraw.Image
+	view *geom.Mat4
+	proj *geom.Mat4
+	near float64
+}
+
+// newProjector returns a new projector for camera c drawing on dst.
+func newProjector(dst stddraw.Image, c *Camera) *projector {
+	b := dst.Bounds()
+	proj := ScreenTransf(c.Frustum(), b.Dx(), b.Dy())
+	proj.Mul(c.ProjTransf())
+	return &projector{dst, c.CamTransf(), proj, c.Near}
+}
+
+// line draws a line between a and b in world coordinates. The part in front
+// of the camera's near plane is cut off.
+func (p *projector) line(a, b *geom.Vec3, c color.Color) {
+	va := p.view.Transf(geom.NewVec4(a[0], a[1], a[2]))
+	vb := p.view.Transf(geom.NewVec4(b[0], b[1], b[2]))
+	da := -va[2]/va[3] - p.near
+	db := -vb[2]/vb[3] - p.near
+	if da < 0 && db < 0 {
+		return
+	}
+	if da < 0 || db < 0 {
+		t := da / (da - db)
+		v := geom.Vec4{}
+		for k := range v {
+			v[k] = va[k] + t*(vb[k]-va[k])
+		}
+		if da < 0 {
+			va = &v
+		} else {
+			vb = &v
+		}
+	}
+	sa := p.proj.Transf(va)
+	sb := p.proj.Transf(vb)
+	draw.LineAa(p.dst, sa[0]/sa[3], sa[1]/sa[3], sb[0]/sb[3], sb[1]/sb[3], c)
+}
+
+// ray draws a line from a in direction d with length l.
+func (p *projector) ray(a, d *geom.Vec3, l float64, c color.Color) {
+	b := *d
+	b.Scale(l)
+	b.Add(a)
+	p.line(a, &b, c)
+}
+
+// box draws the 12 edges between 8 corners. Corner i has bit 0, 1 and 2 set
+// if it is on the positive side along x, y and z.
+func (p *projector) box(cs *[8]geom.Vec3, c color.Color) {
+	for i := 0; i < 8; i++ {
+		for bit := uint(0); bit < 3; bit++ {
+			if j := i | 1<<bit; j != i {
+				p.line(&cs[i], &cs[j], c)
+			}
+		}
+	}
+}
+
+// lightColor returns the color of a light scaled to the displayable range.
+func lightColor(l *Light) color.Color {
+	m := math.Max(l.Color[0], math.Max(l.Color[1], l.Color[2]))
+	if m <= 0 {
+		return color.White
+	}
+	return color.RGBA{
+		uint8(255 * math.Max(0, l.Color[0]/m)),
+		uint8(255 * math.Max(0, l.Color[1]/m)),
+		uint8(255 * math.Max(0, l.Color[2]/m)),
+		255,
+	}
+}
+
+// Draw draws the switched on parts for scene s as seen by camera c onto dst.
+// The camera's aspect ratio should match dst.
+func (d *Debug) Draw(dst stddraw.Image, s *Scene, c *Camera) {
+	p := newProjector(dst, c)
+	if d.Grid {
+		n := float64(d.GridLines)
+		e := n * d.Size
+		for i := -n; i <= n; i++ {
+			x := i * d.Size
+			p.line(&geom.Vec3{x, 0, -e}, &geom.Vec3{x, 0, e}, debugGrid)
+			p.line(&geom.Vec3{-e, 0, x}, &geom.Vec3{e, 0, x}, debugGrid)
+		}
+	}
+	if d.Axes {
+		o := geom.Vec3{0, 0, 0}
+		p.line(&o, &geom.Vec3{d.Size, 0, 0}, debugX)
+		p.line(&o, &geom.Vec3{0, d.Size, 0}, debugY)
+		p.line(&o, &geom.Vec3{0, 0, d.Size}, debugZ)
+	}
+	for _, o := range s.Objects {
+		if d.FaceNormals || d.VertNormals {
+			vs := o.worldVerts()
+			if d.FaceNormals {
+				for _, t := range o.Mesh.Tris {
+					ctr := vs[t[0]]
+					ctr.Add(&vs[t[1]])
+					ctr.Add(&vs[t[2]])
+					ctr.Scale(1.0 / 3)
+					n := triNormal(&vs[t[0]], &vs[t[1]], &vs[t[2]])
+					p.ray(&ctr, n, d.NormalLen, debugFace)
+				}
+			}
+			if d.VertNormals {
+				for i, n := range vertNormals(vs, o.Mesh.Tris) {
+					p.ray(&vs[i], &n, d.NormalLen, debugVert)
+				}
+			}
+		}
+		if d.Bounds && len(o.Mesh.Verts) > 0 {
+			cs := o.corners()
+			p.box(&cs, debugBounds)
+		}
+	}
+	if d.Lights {
+		h := d.Size / 4
+		for _, l := range s.Lights {
+			col := lightColor(l)
+			for k := 0; k < 3; k++ {
+				a := l.Pos
+				b := l.Pos
+				a[k] -= h
+				b[k] += h
+				p.line(&a, &b, col)
+			}
+		}
+	}
+	for _, f := range d.Frustums {
+		cs := f.corners()
+		p.box(&cs, debugFrustum)
+		for i := 0; i < 4; i++ {
+			p.line(&f.Eye, &cs[i], debugFrustum)
+		}
+	}
+}
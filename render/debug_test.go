@@ -0,0 +1,106 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"image"
+	"math"
+	"testing"
+)
+
+// lit returns the number of pixels with non-zero red, green or blue.
+func lit(img *image.RGBA) int {
+	n := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] > 0 || img.Pix[i+1] > 0 || img.Pix[i+2] > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestBounds(t *testing.T) {
+	min, max := NewCube().Bounds()
+	if *min != (geom.Vec3{-0.5, -0.5, -0.5}) || *max != (geom.Vec3{0.5, 0.5, 0.5}) {
+		t.Errorf("expected '%v' and '%v' but got '%v' and '%v'",
+			geom.Vec3{-0.5, -0.5, -0.5}, geom.Vec3{0.5, 0.5, 0.5}, *min, *max)
+	}
+}
+
+func TestVertNormals(t *testing.T) {
+	m := NewCube()
+	ns := vertNormals(m.Verts, m.Tris)
+	for i, n := range ns {
+		// Corners point diagonally outwards
+		should := m.Verts[i]
+		should.Norm()
+		for k := range n {
+			if math.Abs(n[k]-should[k]) > 1e-9 {
+				t.Errorf("expected '%v' but got '%v'", should, n)
+				break
+			}
+		}
+	}
+}
+
+func TestProjectorLine(t *testing.T) {
+	c := NewDefCam()
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	p := newProjector(img, c)
+	// Horizontal line through the middle of the screen
+	p.line(&geom.Vec3{-1, 0, -2}, &geom.Vec3{1, 0, -2}, debugX)
+	if img.RGBAAt(5, 5).R == 0 && img.RGBAAt(5, 4).R == 0 {
+		t.Errorf("expected line in the middle")
+	}
+	// Behind the camera
+	img = image.NewRGBA(image.Rect(0, 0, 10, 10))
+	p = newProjector(img, c)
+	p.line(&geom.Vec3{-1, 0, 2}, &geom.Vec3{1, 0, 2}, debugX)
+	if n := lit(img); n != 0 {
+		t.Errorf("expected '%v' pixels but got '%v'", 0, n)
+	}
+	// From behind to the front, only the front part is drawn in the lower half
+	img = image.NewRGBA(image.Rect(0, 0, 10, 10))
+	p = newProjector(img, c)
+	p.line(&geom.Vec3{0, -1, 2}, &geom.Vec3{0, -1, -10}, debugX)
+	for y := 0; y < 5; y++ {
+		if img.RGBAAt(5, y).R != 0 || img.RGBAAt(4, y).R != 0 {
+			t.Errorf("expected nothing at row '%v'", y)
+		}
+	}
+	if n := lit(img); n == 0 {
+		t.Errorf("expected visible part of line")
+	}
+}
+
+func TestDebugDraw(t *testing.T) {
+	c := NewDefCam()
+	c.Eye = geom.Vec3{0, 2, 5}
+	s := NewScene()
+	s.Add(NewObject(NewCube(), nil))
+	s.AddLight(&Light{Pos: geom.Vec3{1, 1, 1}, Color: geom.Vec3{1, 1, 1}})
+	d := &Debug{Size: 1, GridLines: 2, NormalLen: 0.2}
+	img := image.NewRGBA(image.Rect(0, 0, 50, 50))
+	d.Draw(img, s, c)
+	if n := lit(img); n != 0 {
+		t.Errorf("expected '%v' pixels but got '%v'", 0, n)
+	}
+	// Every part draws something
+	for _, on := range []*bool{&d.Axes, &d.Grid, &d.FaceNormals, &d.VertNormals, &d.Bounds, &d.Lights} {
+		*on = true
+		img = image.NewRGBA(image.Rect(0, 0, 50, 50))
+		d.Draw(img, s, c)
+		if n := lit(img); n == 0 {
+			t.Errorf("expected pixels")
+		}
+		*on = false
+	}
+	f := NewDefCam()
+	f.Eye = geom.Vec3{0, 0, 3}
+	f.Far = 2
+	d.Frustums = []*Camera{f}
+	img = image.NewRGBA(image.Rect(0, 0, 50, 50))
+	d.Draw(img, s, c)
+	if n := lit(img); n == 0 {
+		t.Errorf("expected pixels")
+	}
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+)
+
+// Ray returns the ray from the camera through the point (x,y) on a screen of w
+// times h pixels, as used by PerspTransf. The center of pixel (i,j) is at
+// (i+0.5,j+0.5). The ray starts on the near plane, so nothing is hit that is
+// clipped when rendering, and its direction has unit length.
+//
+// The perspective projection can't be inverted since it loses depth. Instead
+// the screen and camera transformations are inverted to get the point on the
+// near plane in world coordinates.
+func (c *Camera) Ray(x, y float64, w, h int) *geom.Ray {
+	return c.rayGen(w, h).ray(x, y)
+}
+
+// rayGen generates rays from a camera through points on a screen.
+type rayGen struct {
+	screen *geom.Mat4
+	view   *geom.Mat4
+	eye    geom.Vec3
+	near   float64
+}
+
+// rayGen returns a new ray generator for camera c and a screen of w times h
+// pixels.
+func (c *Camera) rayGen(w, h int) *rayGen {
+	screen, _ := ScreenTransf(c.Frustum(), w, h).Inv()
+	view, _ := c.CamTransf().Inv()
+	return &rayGen{screen, view, c.Eye, c.Near}
+}
+
+// ray returns the ray through point (x,y) like Camera.Ray.
+func (g *rayGen) ray(x, y float64) *geom.Ray {
+	p := g.screen.Transf(geom.NewVec4(x, y, 0))
+	p[2] = -g.near
+	p = g.view.Transf(p)
+	p.Norm()
+	r := &geom.Ray{Orig: geom.Vec3{p[0], p[1], p[2]}}
+	r.Dir = r.Orig
+	r.Dir.Sub(&g.eye)
+	r.Dir.Norm()
+	return r
+}
+
+// Hit is where a ray hits a triangle of an object.
+type Hit struct {
+
+	// Object that is hit.
+	Object *Object
+
+	// Tri is the index of the triangle hit in the object's mesh.
+	Tri int
+
+	// Dist is the distance from the ray's origin in lengths of its direction.
+	Dist float64
+
+	// Pos is the position of the hit in world coordinates.
+	Pos geom.Vec3
+
+	// U and V are the barycentric coordinates of the hit for the triangle's
+	// second and third vertex, the one for the first vertex is 1-U-V. They can
+	// be used to interpolate values given per vertex.
+	U, V float64
+}
+
+// worldBounds returns the minimum and maximum corner of the axis-aligned box
+// in world coordinates that contains the object's transformed bounds.
+func (o *Object) worldBounds() (*geom.Vec3, *geom.Vec3) {
+	var min, max geom.Vec3
+	for i, c := range o.corners() {
+		for k := 0; k < 3; k++ {
+			if i == 0 || c[k] < min[k] {
+				min[k] = c[k]
+			}
+			if i == 0 || c[k] > max[k] {
+				max[k] = c[k]
+			}
+		}
+	}
+	return &min, &max
+}
+
+// Pick returns the nearest hit of ray r with any triangle in the scene, or nil
+// if nothing is hit. Both sides of triangles are hit, also of translucent
+// ones. Objects whose bounding box is missed or farther away than the nearest
+// hit so far are skipped.
+func (s *Scene) Pick(r *geom.Ray) *Hit {
+	var hit *Hit
+	for _, o := range s.Objects {
+		if len(o.Mesh.Verts) == 0 {
+			continue
+		}
+		min, max := o.worldBounds()
+		tmin, _, ok := r.IntersectBox(min, max)
+		if !ok || hit != nil && tmin > hit.Dist {
+			continue
+		}
+		vs := o.worldVerts()
+		for i, t := range o.Mesh.Tris {
+			d, u, v, ok := r.IntersectTri(&vs[t[0]], &vs[t[1]], &vs[t[2]])
+			if !ok || hit != nil && d >= hit.Dist {
+				continue
+			}
+			hit = &Hit{Object: o, Tri: i, Dist: d, Pos: *r.At(d), U: u, V: v}
+		}
+	}
+	return hit
+}
@@ -0,0 +1,68 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+	"testing"
+)
+
+// nearVec returns true if v and w differ by less than 1e-9 in all components.
+func nearVec(v, w *geom.Vec3) bool {
+	for k := range v {
+		if math.Abs(v[k]-w[k]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCamRay(t *testing.T) {
+	c := NewDefCam()
+	c.Eye = geom.Vec3{1, 2, 3}
+	c.At = geom.Vec3{1, 2, 0}
+	c.Ar = 2
+	r := c.Ray(100, 50, 200, 100)
+	if !nearVec(&r.Orig, &geom.Vec3{1, 2, 2}) || !nearVec(&r.Dir, &geom.Vec3{0, 0, -1}) {
+		t.Errorf("expected '%v' but got '%v'", geom.Ray{
+			Orig: geom.Vec3{1, 2, 2}, Dir: geom.Vec3{0, 0, -1}}, *r)
+	}
+	// Points along the ray project back to the same screen position
+	m := c.PerspTransf(200, 100)
+	r = c.Ray(20.5, 70.5, 200, 100)
+	for _, d := range []float64{0, 1, 10} {
+		p := r.At(d)
+		s := m.Transf(geom.NewVec4(p[0], p[1], p[2]))
+		s.Norm()
+		if math.Abs(s[0]-20.5) > 1e-9 || math.Abs(s[1]-70.5) > 1e-9 {
+			t.Errorf("expected '%v' but got '%v'", [2]float64{20.5, 70.5}, [2]float64{s[0], s[1]})
+		}
+	}
+}
+
+func TestPick(t *testing.T) {
+	near := NewObject(NewCube(), nil)
+	near.Transf = *TranslTransf(&geom.Vec3{0, 0, -3})
+	far := NewObject(NewCube(), nil)
+	far.Transf = *TranslTransf(&geom.Vec3{0, 0, -5})
+	s := NewScene()
+	s.Add(far, near)
+	r := &geom.Ray{Orig: geom.Vec3{0.2, -0.2, 0}, Dir: geom.Vec3{0, 0, -1}}
+	h := s.Pick(r)
+	if h == nil {
+		t.Fatal("expected hit")
+	}
+	// Lower right triangle of the front face
+	if h.Object != near || h.Tri != 2 {
+		t.Errorf("expected '%v' but got '%v'", [2]interface{}{near, 2}, [2]interface{}{h.Object, h.Tri})
+	}
+	if math.Abs(h.Dist-2.5) > 1e-9 || !nearVec(&h.Pos, &geom.Vec3{0.2, -0.2, -2.5}) {
+		t.Errorf("expected '%v' but got '%v'", 2.5, h.Dist)
+	}
+	if math.Abs(h.U-0.4) > 1e-9 || math.Abs(h.V-0.3) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", [2]float64{0.4, 0.3}, [2]float64{h.U, h.V})
+	}
+	r.Orig = geom.Vec3{2, 0, 0}
+	if h := s.Pick(r); h != nil {
+		t.Errorf("expected no hit but got '%v'", *h)
+	}
+}
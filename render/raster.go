@@ -0,0 +1,623 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/draw"
+	"github.com/amsibamsi/three/image"
+	"github.com/amsibamsi/three/math/geom"
+	"image/color"
+	stddraw "image/draw"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Aa configures anti-aliasing of a framebuffer. Coverage and depth are
+// determined at several sample positions per pixel and the final pixel color is
+// the average of its samples.
+type Aa struct {
+
+	// Samples are the sample positions within a pixel with (0,0) at the top left
+	// and (1,1) at the bottom right corner.
+	Samples [][2]float64
+
+	// Multisample shades a triangle only once per pixel at the pixel center and
+	// stores the color in all samples it covers (MSAA). Otherwise it is shaded
+	// separately for every sample (supersampling).
+	Multisample bool
+}
+
+// OrderedGrid returns n*n sample positions on a regular grid.
+func OrderedGrid(n int) [][2]float64 {
+	s := make([][2]float64, 0, n*n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			s = append(s, [2]float64{
+				(float64(x) + 0.5) / float64(n),
+				(float64(y) + 0.5) / float64(n),
+			})
+		}
+	}
+	return s
+}
+
+// RotatedGrid returns 4 sample positions on a grid rotated by about 26.6
+// degrees. Unlike an ordered grid with the same number of samples every sample
+// has its own row and column, which gives 4 steps for nearly horizontal and
+// vertical edges.
+func RotatedGrid() [][2]float64 {
+	return [][2]float64{
+		{0.375, 0.125},
+		{0.875, 0.375},
+		{0.125, 0.625},
+		{0.625, 0.875},
+	}
+}
+
+// NewSsaa returns anti-aliasing with supersampling at the given sample
+// positions.
+func NewSsaa(samples [][2]float64) *Aa {
+	return &Aa{Samples: samples}
+}
+
+// NewMsaa returns anti-aliasing with multisampling at the given sample
+// positions.
+func NewMsaa(samples [][2]float64) *Aa {
+	return &Aa{Samples: samples, Multisample: true}
+}
+
+// Id identifies the triangle drawn at a pixel.
+type Id struct {
+
+	// Object is the index of the object in the scene's objects plus 1. It is 0
+	// where nothing has been drawn.
+	Object int
+
+	// Tri is the index of the triangle in the object's mesh.
+	Tri int
+}
+
+// Framebuffer receives the result of rasterizing a scene. It holds the color of
+// every pixel with high dynamic range and a depth buffer to resolve which
+// surface is visible.
+type Framebuffer struct {
+
+	// Color holds the linear color of every pixel. Values are not limited to
+	// [0,1] and need tone mapping for display.
+	Color *image.FloatImage
+
+	// Depth holds for every pixel the reciprocal of the distance to the
+	// nearest drawn surface along the camera's looking direction. It is 0
+	// where nothing has been drawn, bigger values are closer.
+	Depth []float64
+
+	// Ids holds for every pixel which triangle is drawn at its center, nil if
+	// disabled. It tells in constant time what is visible at a pixel.
+	Ids []Id
+
+	// Depth at the pixel centers for the ids if anti-aliased
+	idDepth []float64
+
+	// Workers is the number of goroutines rasterizing in parallel. The
+	// vertices of large meshes are transformed in chunks and the screen is
+	// split into tiles that are drawn independently. 1 draws everything in
+	// the calling goroutine without tiles, 0 or less uses GOMAXPROCS. Tiles
+	// are drawn in scene order, so the image doesn't depend on the count.
+	Workers int
+
+	// Anti-aliasing, nil if disabled
+	aa *Aa
+
+	// Color and depth of each sample if anti-aliased. The samples of a pixel
+	// are stored consecutively.
+	sampleColor []float32
+	sampleDepth []float64
+}
+
+// NewFramebuffer returns a new framebuffer with the given width and height,
+// black and with empty depth.
+func NewFramebuffer(w, h int) *Framebuffer {
+	return &Framebuffer{
+		Color: image.NewFloatImage(w, h),
+		Depth: make([]float64, w*h),
+	}
+}
+
+// NewFramebufferAa works like NewFramebuffer, but with the given
+// anti-aliasing.
+func NewFramebufferAa(w, h int, aa *Aa) *Framebuffer {
+	f := NewFramebuffer(w, h)
+	f.SetAa(aa)
+	return f
+}
+
+// SetAa sets the anti-aliasing, nil disables it. The framebuffer is cleared to
+// black.
+func (f *Framebuffer) SetAa(aa *Aa) {
+	f.aa = aa
+	f.sampleColor = nil
+	f.sampleDepth = nil
+	if aa != nil {
+		n := f.Width() * f.Height() * len(aa.Samples)
+		f.sampleColor = make([]float32, 4*n)
+		f.sampleDepth = make([]float64, n)
+	}
+	f.Clear(&geom.Vec3{0, 0, 0})
+}
+
+// SetIds enables or disables the id buffer. The framebuffer is cleared to
+// black.
+func (f *Framebuffer) SetIds(on bool) {
+	f.Ids = nil
+	f.idDepth = nil
+	if on {
+		f.Ids = make([]Id, f.Width()*f.Height())
+		f.idDepth = make([]float64, f.Width()*f.Height())
+	}
+	f.Clear(&geom.Vec3{0, 0, 0})
+}
+
+// Aa returns the anti-aliasing or nil if it is disabled.
+func (f *Framebuffer) Aa() *Aa {
+	return f.aa
+}
+
+// Width returns the width of the framebuffer.
+func (f *Framebuffer) Width() int {
+	return f.Color.Width
+}
+
+// Height returns the height of the framebuffer.
+func (f *Framebuffer) Height() int {
+	return f.Color.Height
+}
+
+// Clear sets all pixels to the given opaque color and empties the depth and id
+// buffer.
+func (f *Framebuffer) Clear(c *geom.Vec3) {
+	pix := [4]float32{float32(c[0]), float32(c[1]), float32(c[2]), 1}
+	f.Color.Clear(pix)
+	for i := range f.Depth {
+		f.Depth[i] = 0
+	}
+	for i := 0; i < len(f.sampleColor); i += 4 {
+		copy(f.sampleColor[i:i+4], pix[:])
+	}
+	for i := range f.sampleDepth {
+		f.sampleDepth[i] = 0
+	}
+	for i := range f.Ids {
+		f.Ids[i] = Id{}
+		f.idDepth[i] = 0
+	}
+}
+
+// ObjectAt returns the object and the index of its triangle drawn at pixel
+// (x,y), or nil and -1 if nothing is drawn there or the id buffer is disabled.
+// The scene must be the one rasterized.
+func (f *Framebuffer) ObjectAt(s *Scene, x, y int) (*Object, int) {
+	if f.Ids == nil || x < 0 || y < 0 || x >= f.Width() || y >= f.Height() {
+		return nil, -1
+	}
+	id := f.Ids[y*f.Width()+x]
+	if id.Object == 0 || id.Object > len(s.Objects) {
+		return nil, -1
+	}
+	return s.Objects[id.Object-1], id.Tri
+}
+
+// Outline draws the outline of object o with color c on dst, e.g. to show it
+// is selected. Dst is usually the image or window the framebuffer is resolved
+// to. The outline consists of the pixels where the object is visible next to a
+// pixel where it is not, so it follows the visible silhouette, including edges
+// where other objects in front cut it off. Nothing is drawn if the id buffer is
+// disabled or o is not in the scene.
+func (f *Framebuffer) Outline(dst stddraw.Image, s *Scene, o *Object, c color.Color) {
+	obj := 0
+	for i, so := range s.Objects {
+		if so == o {
+			obj = i + 1
+		}
+	}
+	if f.Ids == nil || obj == 0 {
+		return
+	}
+	w := f.Width()
+	h := f.Height()
+	is := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && f.Ids[y*w+x].Object == obj
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if is(x, y) && !(is(x-1, y) && is(x+1, y) && is(x, y-1) && is(x, y+1)) {
+				draw.Pixel(dst, x, y, c)
+			}
+		}
+	}
+}
+
+// tileSize is the width and height of the tiles drawn in parallel.
+const tileSize = 32
+
+// tile is a rectangle of pixels from (x0,y0) to (x1,y1), excluding the
+// latter.
+type tile struct {
+	x0, y0, x1, y1 int
+}
+
+// tiles returns the tiles covering the framebuffer row by row.
+func (f *Framebuffer) tiles() []tile {
+	var ts []tile
+	for y := 0; y < f.Height(); y += tileSize {
+		for x := 0; x < f.Width(); x += tileSize {
+			ts = append(ts, tile{
+				x, y,
+				int(math.Min(float64(x+tileSize), float64(f.Width()))),
+				int(math.Min(float64(y+tileSize), float64(f.Height()))),
+			})
+		}
+	}
+	return ts
+}
+
+// resolve computes the color of every pixel in tile t as the average of its
+// samples and the depth as the depth of its nearest sample. Nothing is done
+// without anti-aliasing.
+func (f *Framebuffer) resolve(t tile) {
+	if f.aa == nil {
+		return
+	}
+	n := len(f.aa.Samples)
+	inv := 1 / float32(n)
+	for y := t.y0; y < t.y1; y++ {
+		for x := t.x0; x < t.x1; x++ {
+			i := y*f.Width() + x
+			var sum [4]float32
+			d := 0.0
+			for s := i * n; s < (i+1)*n; s++ {
+				for k := 0; k < 4; k++ {
+					sum[k] += f.sampleColor[4*s+k]
+				}
+				d = math.Max(d, f.sampleDepth[s])
+			}
+			for k := 0; k < 4; k++ {
+				f.Color.Pix[4*i+k] = sum[k] * inv
+			}
+			f.Depth[i] = d
+		}
+	}
+}
+
+// vert is a vertex after projection to the screen. Iw is the reciprocal of the
+// homogeneous w, which is the distance from the camera along its looking
+// direction.
+type vert struct {
+	x, y, iw float64
+}
+
+// clipPlane clips a convex polygon in camera coordinates against the plane
+// where dist(v) = 0 and keeps the part where dist(v) >= 0 (Sutherland-Hodgman).
+func clipPlane(poly []geom.Vec3, dist func(v *geom.Vec3) float64) []geom.Vec3 {
+	out := make([]geom.Vec3, 0, len(poly)+1)
+	for i := range poly {
+		a := &poly[i]
+		b := &poly[(i+1)%len(poly)]
+		da := dist(a)
+		db := dist(b)
+		if da >= 0 {
+			out = append(out, *a)
+		}
+		if (da >= 0) != (db >= 0) {
+			t := da / (da - db)
+			p := *b
+			p.Sub(a)
+			p.Scale(t)
+			p.Add(a)
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// clipNearFar clips a polygon in camera coordinates to the space between the
+// camera's near and far plane.
+func (c *Camera) clipNearFar(poly []geom.Vec3) []geom.Vec3 {
+	poly = clipPlane(poly, func(v *geom.Vec3) float64 {
+		return -v[2] - c.Near
+	})
+	if len(poly) < 3 {
+		return nil
+	}
+	return clipPlane(poly, func(v *geom.Vec3) float64 {
+		return v[2] + c.Far
+	})
+}
+
+// edge returns twice the signed area of the triangle (a,b,p). Its sign tells on
+// which side of the line from a to b the point p is.
+func edge(ax, ay, bx, by, px, py float64) float64 {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// owns decides for points exactly on the edge from a to b whether the edge
+// belongs to the triangle. A shared edge is traversed in opposite directions by
+// the two triangles, so it is owned by exactly one of them and no pixel is
+// drawn twice.
+func owns(ax, ay, bx, by float64) bool {
+	dx := bx - ax
+	dy := by - ay
+	return dy > 0 || (dy == 0 && dx < 0)
+}
+
+// inside tells whether a point with edge value e is inside regarding that edge.
+func inside(e float64, own bool) bool {
+	return e > 0 || (e == 0 && own)
+}
+
+// shader returns the color of a triangle at screen position (x,y),
+// premultiplied with alpha.
+type shader func(x, y float64) [4]float32
+
+// flat returns a shader with the same color everywhere and alpha a.
+func flat(c *geom.Vec3, a float64) shader {
+	pix := [4]float32{
+		float32(c[0] * a),
+		float32(c[1] * a),
+		float32(c[2] * a),
+		float32(a),
+	}
+	return func(x, y float64) [4]float32 {
+		return pix
+	}
+}
+
+// over draws the color src over dst.
+func over(dst []float32, src [4]float32) {
+	k := 1 - src[3]
+	for i := range src {
+		dst[i] = src[i] + dst[i]*k
+	}
+}
+
+// rasterTri fills a projected triangle with colors from the shader. Without
+// anti-aliasing a pixel is drawn if its center is inside the triangle and it is
+// closer than what has been drawn before. With anti-aliasing the same is
+// decided for every sample. Opaque colors replace what has been drawn before
+// and update the depth. Translucent colors are drawn over it and leave the
+// depth as it is. If enabled the id is stored wherever the pixel center is
+// drawn, so translucent triangles in front are seen as well. Only pixels within
+// tile t are drawn.
+func (f *Framebuffer) rasterTri(a, b, c *vert, sh shader, id Id, t tile) {
+	area := edge(a.x, a.y, b.x, b.y, c.x, c.y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+	w := f.Width()
+	minx := int(math.Max(float64(t.x0), math.Floor(math.Min(a.x, math.Min(b.x, c.x)))))
+	maxx := int(math.Min(float64(t.x1-1), math.Ceil(math.Max(a.x, math.Max(b.x, c.x)))))
+	miny := int(math.Max(float64(t.y0), math.Floor(math.Min(a.y, math.Min(b.y, c.y)))))
+	maxy := int(math.Min(float64(t.y1-1), math.Ceil(math.Max(a.y, math.Max(b.y, c.y)))))
+	ownA := owns(b.x, b.y, c.x, c.y)
+	ownB := owns(c.x, c.y, a.x, a.y)
+	ownC := owns(a.x, a.y, b.x, b.y)
+	// depth returns the depth at (x,y) if it is inside the triangle, else 0
+	depth := func(x, y float64) float64 {
+		wa := edge(b.x, b.y, c.x, c.y, x, y)
+		wb := edge(c.x, c.y, a.x, a.y, x, y)
+		wc := edge(a.x, a.y, b.x, b.y, x, y)
+		if !inside(wa, ownA) || !inside(wb, ownB) || !inside(wc, ownC) {
+			return 0
+		}
+		return (wa*a.iw + wb*b.iw + wc*c.iw) / area
+	}
+	for py := miny; py <= maxy; py++ {
+		for px := minx; px <= maxx; px++ {
+			i := py*w + px
+			cx := float64(px) + 0.5
+			cy := float64(py) + 0.5
+			if f.aa == nil {
+				iw := depth(cx, cy)
+				if iw <= f.Depth[i] {
+					continue
+				}
+				pix := sh(cx, cy)
+				if f.Ids != nil {
+					f.Ids[i] = id
+				}
+				if pix[3] < 1 {
+					over(f.Color.Pix[4*i:4*i+4], pix)
+					continue
+				}
+				f.Depth[i] = iw
+				copy(f.Color.Pix[4*i:4*i+4], pix[:])
+				continue
+			}
+			if f.Ids != nil {
+				if iw := depth(cx, cy); iw > f.idDepth[i] {
+					f.Ids[i] = id
+					if sh(cx, cy)[3] == 1 {
+						f.idDepth[i] = iw
+					}
+				}
+			}
+			n := len(f.aa.Samples)
+			shaded := false
+			var pix [4]float32
+			for k, s := range f.aa.Samples {
+				x := float64(px) + s[0]
+				y := float64(py) + s[1]
+				iw := depth(x, y)
+				j := i*n + k
+				if iw <= f.sampleDepth[j] {
+					continue
+				}
+				if !f.aa.Multisample {
+					pix = sh(x, y)
+				} else if !shaded {
+					pix = sh(cx, cy)
+					shaded = true
+				}
+				if pix[3] < 1 {
+					over(f.sampleColor[4*j:4*j+4], pix)
+					continue
+				}
+				f.sampleDepth[j] = iw
+				copy(f.sampleColor[4*j:4*j+4], pix[:])
+			}
+		}
+	}
+}
+
+// poly is a clipped and projected polygon waiting to be drawn.
+type poly struct {
+	verts []vert
+	sh    shader
+	id    Id
+
+	// Distance from the camera, used for sorting
+	dist float64
+}
+
+// draw rasterizes the part of the polygon within tile t as a fan of
+// triangles.
+func (p *poly) draw(fb *Framebuffer, t tile) {
+	for k := 1; k < len(p.verts)-1; k++ {
+		fb.rasterTri(&p.verts[0], &p.verts[k], &p.verts[k+1], p.sh, p.id, t)
+	}
+}
+
+// bounds returns the range of tile columns and rows the polygon overlaps on
+// a screen of w times h pixels, excluding the maximum.
+func (p *poly) bounds(w, h int) (int, int, int, int) {
+	minx, miny := math.Inf(1), math.Inf(1)
+	maxx, maxy := math.Inf(-1), math.Inf(-1)
+	for _, v := range p.verts {
+		minx = math.Min(minx, v.x)
+		maxx = math.Max(maxx, v.x)
+		miny = math.Min(miny, v.y)
+		maxy = math.Max(maxy, v.y)
+	}
+	cols := (w + tileSize - 1) / tileSize
+	rows := (h + tileSize - 1) / tileSize
+	clamp := func(v float64, max int) int {
+		return int(math.Max(0, math.Min(float64(max), v)))
+	}
+	return clamp(math.Floor(minx/tileSize), cols), clamp(math.Floor(miny/tileSize), rows),
+		clamp(math.Floor(maxx/tileSize)+1, cols), clamp(math.Floor(maxy/tileSize)+1, rows)
+}
+
+// backToFront sorts polygons from the farthest to the nearest.
+type backToFront []*poly
+
+func (b backToFront) Len() int           { return len(b) }
+func (b backToFront) Less(i, j int) bool { return b[i].dist > b[j].dist }
+func (b backToFront) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// Rasterize draws all objects of the scene as seen by the camera into the
+// framebuffer. Triangles are clipped at the camera's near and far plane,
+// shaded flat with the scene's lights and hidden surfaces are removed with the
+// depth buffer. The framebuffer is not cleared before. With anti-aliasing the
+// samples are resolved to pixel colors at the end.
+//
+// Opaque triangles are drawn first. Translucent triangles are then sorted by
+// the distance of their centers from the camera and drawn from back to front,
+// each over what is behind it. Intersecting translucent triangles can't be
+// sorted correctly and may show the wrong one in front.
+//
+// If the framebuffer's id buffer is enabled it receives the object and
+// triangle visible at every pixel center.
+//
+// With several workers triangles are first sorted into the screen tiles they
+// overlap. The tiles are then drawn in parallel, each with its triangles in
+// the same order as without workers, so the result is the same.
+func Rasterize(s *Scene, c *Camera, fb *Framebuffer) {
+	view := c.CamTransf()
+	proj := ScreenTransf(c.Frustum(), fb.Width(), fb.Height())
+	proj.Mul(c.ProjTransf())
+	var opaque, translucent []*poly
+	// Vertices of the current object in world and camera coordinates
+	var worlds, cams []geom.Vec3
+	for j, o := range s.Objects {
+		mat := o.material()
+		mv := *view
+		mv.Mul(&o.Transf)
+		n := len(o.Mesh.Verts)
+		if cap(worlds) < n {
+			worlds = make([]geom.Vec3, n)
+			cams = make([]geom.Vec3, n)
+		}
+		worlds = worlds[:n]
+		cams = cams[:n]
+		o.Transf.ParTransfPoints(worlds, o.Mesh.Verts, fb.Workers)
+		mv.ParTransfPoints(cams, o.Mesh.Verts, fb.Workers)
+		for i, t := range o.Mesh.Tris {
+			world := [3]geom.Vec3{worlds[t[0]], worlds[t[1]], worlds[t[2]]}
+			cam := []geom.Vec3{cams[t[0]], cams[t[1]], cams[t[2]]}
+			dist := -(cam[0][2] + cam[1][2] + cam[2][2]) / 3
+			cam = c.clipNearFar(cam)
+			if len(cam) < 3 {
+				continue
+			}
+			col := s.shadeFlat(&world[0], &world[1], &world[2], mat, &c.Eye)
+			p := &poly{
+				verts: make([]vert, len(cam)),
+				sh:    flat(&col, 1-mat.Transparency),
+				id:    Id{j + 1, i},
+				dist:  dist,
+			}
+			for k := range cam {
+				v := proj.Transf(geom.NewVec4(cam[k][0], cam[k][1], cam[k][2]))
+				p.verts[k] = vert{v[0] / v[3], v[1] / v[3], 1 / v[3]}
+			}
+			if mat.Transparency > 0 {
+				translucent = append(translucent, p)
+			} else {
+				opaque = append(opaque, p)
+			}
+		}
+	}
+	sort.Stable(backToFront(translucent))
+	polys := append(opaque, translucent...)
+	workers := numWorkers(fb.Workers)
+	if workers == 1 {
+		all := tile{0, 0, fb.Width(), fb.Height()}
+		for _, p := range polys {
+			p.draw(fb, all)
+		}
+		fb.resolve(all)
+		return
+	}
+	tiles := fb.tiles()
+	cols := (fb.Width() + tileSize - 1) / tileSize
+	bins := make([][]*poly, len(tiles))
+	for _, p := range polys {
+		x0, y0, x1, y1 := p.bounds(fb.Width(), fb.Height())
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				bins[y*cols+x] = append(bins[y*cols+x], p)
+			}
+		}
+	}
+	next := make(chan int, len(tiles))
+	for i := range tiles {
+		next <- i
+	}
+	close(next)
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				for _, p := range bins[i] {
+					p.draw(fb, tiles[i])
+				}
+				fb.resolve(tiles[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,281 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"image"
+	"image/color"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// quad returns an object with a square of size 2 facing the camera at distance
+// d with the given color as emission.
+func quad(d float64, r, g, b float64) *Object {
+	m := &Mesh{
+		Verts: []geom.Vec3{{-1, -1, -d}, {1, -1, -d}, {1, 1, -d}, {-1, 1, -d}},
+		Tris:  [][3]int{{0, 1, 2}, {0, 2, 3}},
+	}
+	return NewObject(m, &Material{Emission: geom.Vec3{r, g, b}})
+}
+
+func TestRasterizeDepth(t *testing.T) {
+	c := NewDefCam()
+	fb := NewFramebuffer(10, 10)
+	s := NewScene()
+	s.Add(quad(2, 1, 0, 0), quad(4, 0, 1, 0))
+	Rasterize(s, c, fb)
+	col := fb.Color.At(5, 5)
+	r := [4]float32{1, 0, 0, 1}
+	if col != r {
+		t.Errorf("expected '%v' but got '%v'", r, col)
+	}
+	if fb.Depth[55] != 0.5 {
+		t.Errorf("expected '%v' but got '%v'", 0.5, fb.Depth[55])
+	}
+}
+
+// glass returns a quad like quad with the given transparency.
+func glass(d, r, g, b, tr float64) *Object {
+	o := quad(d, r, g, b)
+	o.Material.Transparency = tr
+	return o
+}
+
+func TestRasterizeTranslucent(t *testing.T) {
+	// Same result regardless of the order in the scene
+	for _, objs := range [][]*Object{
+		{quad(6, 0, 0, 1), glass(4, 1, 0, 0, 0.5), glass(2, 0, 1, 0, 0.5)},
+		{glass(2, 0, 1, 0, 0.5), glass(4, 1, 0, 0, 0.5), quad(6, 0, 0, 1)},
+	} {
+		fb := NewFramebuffer(10, 10)
+		s := NewScene()
+		s.Add(objs...)
+		Rasterize(s, NewDefCam(), fb)
+		col := fb.Color.At(5, 5)
+		r := [4]float32{0.25, 0.5, 0.25, 1}
+		if col != r {
+			t.Errorf("expected '%v' but got '%v'", r, col)
+		}
+		// Only the opaque quad sets the depth
+		if d := fb.Depth[55]; d != 1.0/6 {
+			t.Errorf("expected '%v' but got '%v'", 1.0/6, d)
+		}
+	}
+}
+
+func TestRasterizeCoverage(t *testing.T) {
+	c := NewDefCam()
+	fb := NewFramebuffer(10, 10)
+	s := NewScene()
+	// Covers the middle half of the screen, shared diagonal drawn once
+	s.Add(quad(2, 2, 0, 0))
+	Rasterize(s, c, fb)
+	n := 0
+	for _, d := range fb.Depth {
+		if d != 0 {
+			n++
+		}
+	}
+	if n != 25 {
+		t.Errorf("expected '%v' pixels but got '%v'", 25, n)
+	}
+}
+
+func TestRasterizeClip(t *testing.T) {
+	c := NewDefCam()
+	fb := NewFramebuffer(10, 10)
+	s := NewScene()
+	m := &Mesh{
+		Verts: []geom.Vec3{{-1, -1, 0}, {1, -1, -3}, {0, 1, -3}},
+		Tris:  [][3]int{{0, 1, 2}},
+	}
+	s.Add(NewObject(m, nil))
+	Rasterize(s, c, fb)
+	for _, d := range fb.Depth {
+		if d > 1/c.Near {
+			t.Errorf("expected depth at most '%v' but got '%v'", 1/c.Near, d)
+		}
+	}
+}
+
+func TestShadeFlat(t *testing.T) {
+	s := NewScene()
+	s.Ambient = geom.Vec3{0.5, 0.5, 0.5}
+	s.AddLight(&Light{Pos: geom.Vec3{0, 0, 2}, Color: geom.Vec3{4, 8, 0}})
+	mat := &Material{Color: geom.Vec3{1, 0.5, 1}, Emission: geom.Vec3{0, 0, 1}}
+	p0 := geom.Vec3{-1, -1, 0}
+	p1 := geom.Vec3{2, -1, 0}
+	p2 := geom.Vec3{-1, 2, 0}
+	eye := geom.Vec3{0, 0, -1}
+	// Seen from the side facing away from the light only ambient light counts
+	col := s.shadeFlat(&p0, &p1, &p2, mat, &eye)
+	r := geom.Vec3{0.5, 0.25, 1.5}
+	if col != r {
+		t.Errorf("expected '%v' but got '%v'", r, col)
+	}
+	eye = geom.Vec3{0, 0, 1}
+	col = s.shadeFlat(&p0, &p1, &p2, mat, &eye)
+	r = geom.Vec3{1.5, 1.25, 1.5}
+	if col != r {
+		t.Errorf("expected '%v' but got '%v'", r, col)
+	}
+}
+
+func TestOrderedGrid(t *testing.T) {
+	s := OrderedGrid(2)
+	r := [][2]float64{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}}
+	for i := range r {
+		if s[i] != r[i] {
+			t.Errorf("expected '%v' but got '%v'", r, s)
+			break
+		}
+	}
+}
+
+// edgeScene returns a scene with a white quad whose left edge lies at x = 4.5
+// on a framebuffer with width 10.
+func edgeScene() *Scene {
+	s := NewScene()
+	m := &Mesh{
+		Verts: []geom.Vec3{{-0.1, -5, -1}, {5, -5, -1}, {5, 5, -1}, {-0.1, 5, -1}},
+		Tris:  [][3]int{{0, 1, 2}, {0, 2, 3}},
+	}
+	s.Add(NewObject(m, &Material{Emission: geom.Vec3{1, 1, 1}}))
+	return s
+}
+
+var aatests = []struct {
+	aa   *Aa
+	edge float32
+}{
+	{nil, 0},
+	{NewSsaa(OrderedGrid(2)), 0.5},
+	{NewSsaa(RotatedGrid()), 0.5},
+	{NewMsaa(OrderedGrid(4)), 0.5},
+	{NewMsaa(RotatedGrid()), 0.5},
+}
+
+func TestRasterizeAa(t *testing.T) {
+	for _, test := range aatests {
+		fb := NewFramebufferAa(10, 10, test.aa)
+		Rasterize(edgeScene(), NewDefCam(), fb)
+		should := [3]float32{0, test.edge, 1}
+		is := [3]float32{
+			fb.Color.At(3, 5)[0],
+			fb.Color.At(4, 5)[0],
+			fb.Color.At(5, 5)[0],
+		}
+		if is != should {
+			t.Errorf("expected '%v' but got '%v'", should, is)
+		}
+	}
+}
+
+func TestRasterizeIds(t *testing.T) {
+	for _, test := range aatests {
+		for _, front := range []int{0, 1} {
+			objs := []*Object{quad(2, 1, 0, 0), glass(4, 0, 1, 0, 0.5)}
+			if front == 1 {
+				objs = []*Object{quad(4, 1, 0, 0), glass(2, 0, 1, 0, 0.5)}
+			}
+			s := NewScene()
+			s.Add(objs...)
+			fb := NewFramebufferAa(10, 10, test.aa)
+			fb.SetIds(true)
+			Rasterize(s, NewDefCam(), fb)
+			// The nearer quad is seen, also if translucent
+			if o, tri := fb.ObjectAt(s, 5, 5); o != objs[front] || tri != 0 {
+				t.Errorf("expected '%v' but got '%v'", [2]interface{}{objs[front], 0}, [2]interface{}{o, tri})
+			}
+			if o, tri := fb.ObjectAt(s, 0, 0); o != nil || tri != -1 {
+				t.Errorf("expected '%v' but got '%v'", [2]interface{}{nil, -1}, [2]interface{}{o, tri})
+			}
+		}
+	}
+}
+
+func TestOutline(t *testing.T) {
+	s := NewScene()
+	q := quad(2, 1, 0, 0)
+	s.Add(q)
+	fb := NewFramebuffer(10, 10)
+	fb.SetIds(true)
+	Rasterize(s, NewDefCam(), fb)
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	fb.Outline(img, s, q, color.White)
+	// Border of the 5x5 pixels covered
+	if n := lit(img); n != 16 {
+		t.Errorf("expected '%v' pixels but got '%v'", 16, n)
+	}
+	if img.RGBAAt(5, 5).R != 0 || img.RGBAAt(3, 3).R != 255 {
+		t.Errorf("expected only the border drawn")
+	}
+	img = image.NewRGBA(image.Rect(0, 0, 10, 10))
+	fb.Outline(img, s, quad(2, 1, 0, 0), color.White)
+	if n := lit(img); n != 0 {
+		t.Errorf("expected '%v' pixels but got '%v'", 0, n)
+	}
+}
+
+// tiledScene returns a scene of random cubes, every third translucent, with a
+// light, and a camera looking at it with the given aspect ratio.
+func tiledScene(ar float64) (*Scene, *Camera) {
+	r := rand.New(rand.NewSource(0))
+	s := cubes(r, 60)
+	for i, o := range s.Objects {
+		o.Material = NewMaterial(r.Float64(), r.Float64(), r.Float64())
+		if i%3 == 0 {
+			o.Material.Transparency = 0.5
+		}
+	}
+	s.AddLight(&Light{Pos: geom.Vec3{5, 5, 20}, Color: geom.Vec3{300, 300, 300}})
+	c := NewDefCam()
+	c.Eye = geom.Vec3{0, 0, 30}
+	c.At = geom.Vec3{0, 0, 0}
+	c.Ar = ar
+	return s, c
+}
+
+func TestRasterizeParallel(t *testing.T) {
+	s, c := tiledScene(101.0 / 77)
+	for _, test := range aatests {
+		// Negative counts use GOMAXPROCS
+		counts := []int{1, 4, -1}
+		fbs := make([]*Framebuffer, len(counts))
+		for k, workers := range counts {
+			// Size not a multiple of the tiles
+			fbs[k] = NewFramebufferAa(101, 77, test.aa)
+			fbs[k].SetIds(true)
+			fbs[k].Workers = workers
+			Rasterize(s, c, fbs[k])
+		}
+		for k := 1; k < len(fbs); k++ {
+			if !reflect.DeepEqual(fbs[0].Color.Pix, fbs[k].Color.Pix) {
+				t.Errorf("expected same colors with '%v' workers", counts[k])
+			}
+			if !reflect.DeepEqual(fbs[0].Depth, fbs[k].Depth) {
+				t.Errorf("expected same depth with '%v' workers", counts[k])
+			}
+			if !reflect.DeepEqual(fbs[0].Ids, fbs[k].Ids) {
+				t.Errorf("expected same ids with '%v' workers", counts[k])
+			}
+		}
+	}
+}
+
+func benchRasterize(b *testing.B, workers int) {
+	s, c := tiledScene(640.0 / 480)
+	fb := NewFramebufferAa(640, 480, NewMsaa(RotatedGrid()))
+	fb.Workers = workers
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fb.Clear(&geom.Vec3{})
+		Rasterize(s, c, fb)
+	}
+}
+
+func BenchmarkRasterize1(b *testing.B) { benchRasterize(b, 1) }
+func BenchmarkRasterize2(b *testing.B) { benchRasterize(b, 2) }
+func BenchmarkRasterize4(b *testing.B) { benchRasterize(b, 4) }
+func BenchmarkRasterize8(b *testing.B) { benchRasterize(b, 8) }
@@ -3,8 +3,10 @@ package render
 import (
 	"github.com/amsibamsi/three/math/geom"
 	"math"
+	"runtime"
 )
 
+// TranslTransf returns a new matrix that translates vectors by v.
 func TranslTransf(v *geom.Vec3) *geom.Mat4 {
 	return &geom.Mat4{
 		1, 0, 0, v[0],
@@ -14,6 +16,34 @@ func TranslTransf(v *geom.Vec3) *geom.Mat4 {
 	}
 }
 
+// ScaleTransf returns a new matrix that scales vectors by s along each axis.
+func ScaleTransf(s *geom.Vec3) *geom.Mat4 {
+	return &geom.Mat4{
+		s[0], 0, 0, 0,
+		0, s[1], 0, 0,
+		0, 0, s[2], 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotTransf returns a new matrix that rotates vectors by angle a (radian)
+// around the axis given by v through the origin. Looking against the axis the
+// rotation is counter-clockwise.
+func RotTransf(v *geom.Vec3, a float64) *geom.Mat4 {
+	n := *v
+	n.Norm()
+	x, y, z := n[0], n[1], n[2]
+	c := math.Cos(a)
+	s := math.Sin(a)
+	t := 1 - c
+	return &geom.Mat4{
+		t*x*x + c, t*x*y - s*z, t*x*z + s*y, 0,
+		t*x*y + s*z, t*y*y + c, t*y*z - s*x, 0,
+		t*x*z - s*y, t*y*z + s*x, t*z*z + c, 0,
+		0, 0, 0, 1,
+	}
+}
+
 // CoordTransf returns a new matrix that transforms from the orthonormal basis
 // given by the 3 argument axes to the standard basis. It is used to to
 // transform vectors from the world to the camera view.
@@ -52,7 +82,7 @@ type Camera struct {
 	// virtual line to the eye is drawn.
 	Eye geom.Vec3
 
-	// At is the direction to look at from the eye.
+	// At is the point to look at from the eye.
 	At geom.Vec3
 
 	// Up determines the orientation of the view. Up not being perpendicular to
@@ -168,6 +198,58 @@ func (c *Camera) Frustum() *Frustum {
 	return &Frustum{nw, nh, fw, fh}
 }
 
+// corners returns the corners of the frustum in world coordinates. Corner i
+// has bit 0, 1 and 2 set if it is on the right, top and far side.
+func (c *Camera) corners() [8]geom.Vec3 {
+	x, y, z := c.CamAxes()
+	f := c.Frustum()
+	var cs [8]geom.Vec3
+	for i := range cs {
+		d, w, h := c.Near, f.Nwidth, f.Nheight
+		if i&4 != 0 {
+			d, w, h = c.Far, f.Fwidth, f.Fheight
+		}
+		cx := w / 2
+		if i&1 == 0 {
+			cx = -cx
+		}
+		cy := h / 2
+		if i&2 == 0 {
+			cy = -cy
+		}
+		v := c.Eye
+		for k := 0; k < 3; k++ {
+			v[k] += cx*x[k] + cy*y[k] - d*z[k]
+		}
+		cs[i] = v
+	}
+	return cs
+}
+
+// Planes returns the 6 planes bounding the camera's frustum in world
+// coordinates: near, far, left, right, bottom and top. Their normals point to
+// the inside, so everything visible has a positive distance from all planes.
+func (c *Camera) Planes() []geom.Plane {
+	cs := c.corners()
+	faces := [6][3]int{
+		{0, 1, 2}, {4, 5, 6}, {0, 2, 4}, {1, 3, 5}, {0, 1, 4}, {2, 3, 6},
+	}
+	// Center of the frustum to orient the normals
+	var ctr geom.Vec3
+	for i := range cs {
+		ctr.Add(&cs[i])
+	}
+	ctr.Scale(1.0 / 8)
+	ps := make([]geom.Plane, len(faces))
+	for i, f := range faces {
+		ps[i] = *geom.NewPlane(&cs[f[0]], &cs[f[1]], &cs[f[2]])
+		if ps[i].Dist(&ctr) < 0 {
+			ps[i].Flip()
+		}
+	}
+	return ps
+}
+
 // ScreenTransf returns a new matrix that transforms vectors after projection
 // to screen coordinates. The upper left corner of the near rectangle will be
 // (0,0) and the bottom right will be (w,h). If the aspect ratio does not match
@@ -201,3 +283,12 @@ func (c *Camera) PerspTransf(w, h int) *geom.Mat4 {
 	m.Mul(c.CamTransf())
 	return m
 }
+
+// numWorkers returns the number of goroutines to render with when n are
+// requested. Counts below 1 use GOMAXPROCS.
+func numWorkers(n int) int {
+	if n < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
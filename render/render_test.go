@@ -167,3 +167,50 @@ func TestPerspTransf(t *testing.T) {
 		t.Errorf("expected '%v' but got '%v'", r, w)
 	}
 }
+
+func TestScaleTransf(t *testing.T) {
+	m := ScaleTransf(&geom.Vec3{1, 2, 3})
+	v := m.Transf(geom.NewVec4(3, 2, 1))
+	r := geom.Vec4{3, 4, 3, 1}
+	if *v != r {
+		t.Errorf("expected '%v' but got '%v'", r, *v)
+	}
+}
+
+func TestRotTransf(t *testing.T) {
+	m := RotTransf(&geom.Vec3{0, 0, 2}, math.Pi/2)
+	v := m.Transf(geom.NewVec4(1, 0, 5))
+	r := geom.Vec4{0, 1, 5, 1}
+	for i := range r {
+		if math.Abs(v[i]-r[i]) > 1e-12 {
+			t.Errorf("expected '%v' but got '%v'", r, *v)
+			break
+		}
+	}
+}
+
+func TestCamPlanes(t *testing.T) {
+	c := NewDefCam()
+	ps := c.Planes()
+	for _, test := range []struct {
+		v      geom.Vec3
+		inside bool
+	}{
+		{geom.Vec3{0, 0, -2}, true},
+		{geom.Vec3{0.9, -0.9, -1.1}, true},
+		{geom.Vec3{0, 0, -0.5}, false},
+		{geom.Vec3{0, 0, -101}, false},
+		{geom.Vec3{3, 0, -2}, false},
+		{geom.Vec3{0, -3, -2}, false},
+	} {
+		inside := true
+		for i := range ps {
+			if ps[i].Dist(&test.v) < 0 {
+				inside = false
+			}
+		}
+		if inside != test.inside {
+			t.Errorf("expected '%v' but got '%v'", test.inside, inside)
+		}
+	}
+}
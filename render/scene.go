@@ -0,0 +1,316 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+)
+
+// Material describes how a surface reflects and emits light. Colors are linear
+// RGB.
+type Material struct {
+
+	// Color is the fraction of incoming light that is diffusely reflected for
+	// red, green and blue.
+	Color geom.Vec3
+
+	// Emission is the light emitted by the surface itself. Values can be above
+	// 1.
+	Emission geom.Vec3
+
+	// Transparency is the fraction of light from behind that passes through
+	// the surface. 0 is opaque, 1 is invisible. Translucent surfaces are drawn
+	// over the opaque ones from back to front. The path tracer ignores it,
+	// Dielectric surfaces are transparent there.
+	Transparency float64
+
+	// Surface is how the surface scatters light when path traced. The
+	// rasterizer shades all surfaces as diffuse.
+	Surface Surface
+
+	// Roughness blurs the reflections of metal surfaces. 0 is a perfect
+	// mirror.
+	Roughness float64
+
+	// Ior is the index of refraction of dielectric surfaces, e.g. 1.5 for
+	// glass.
+	Ior float64
+}
+
+// Surface is how a surface scatters light.
+type Surface int
+
+const (
+	// Diffuse scatters light equally in all directions (Lambert), tinted by
+	// the material's color.
+	Diffuse Surface = iota
+
+	// Metal reflects light like a mirror, blurred by the roughness and tinted
+	// by the material's color.
+	Metal
+
+	// Dielectric reflects or refracts light like glass or water depending on
+	// the angle (Fresnel), tinted by the material's color.
+	Dielectric
+)
+
+// NewMaterial returns a new diffuse material with the given color and no
+// emission.
+func NewMaterial(r, g, b float64) *Material {
+	return &Material{Color: geom.Vec3{r, g, b}}
+}
+
+// NewMetal returns a new metal material with the given color and roughness.
+func NewMetal(r, g, b, roughness float64) *Material {
+	return &Material{
+		Color:     geom.Vec3{r, g, b},
+		Surface:   Metal,
+		Roughness: roughness,
+	}
+}
+
+// NewDielectric returns a new clear dielectric material with the given index
+// of refraction.
+func NewDielectric(ior float64) *Material {
+	return &Material{
+		Color:   geom.Vec3{1, 1, 1},
+		Surface: Dielectric,
+		Ior:     ior,
+	}
+}
+
+// defMat is used for objects without material.
+var defMat = NewMaterial(0.8, 0.8, 0.8)
+
+// Mesh is a set of triangles sharing vertices.
+type Mesh struct {
+
+	// Verts are the positions of the vertices in object coordinates.
+	Verts []geom.Vec3
+
+	// Tris are the triangles, each given by 3 indices into Verts. Seen from the
+	// front the vertices are in counter-clockwise order.
+	Tris [][3]int
+}
+
+// NewCube returns a new mesh of a cube with side length 1 centered at the
+// origin.
+func NewCube() *Mesh {
+	return &Mesh{
+		Verts: []geom.Vec3{
+			{-0.5, -0.5, -0.5},
+			{0.5, -0.5, -0.5},
+			{0.5, 0.5, -0.5},
+			{-0.5, 0.5, -0.5},
+			{-0.5, -0.5, 0.5},
+			{0.5, -0.5, 0.5},
+			{0.5, 0.5, 0.5},
+			{-0.5, 0.5, 0.5},
+		},
+		Tris: [][3]int{
+			{0, 3, 2}, {0, 2, 1},
+			{4, 5, 6}, {4, 6, 7},
+			{0, 4, 7}, {0, 7, 3},
+			{1, 2, 6}, {1, 6, 5},
+			{0, 1, 5}, {0, 5, 4},
+			{3, 7, 6}, {3, 6, 2},
+		},
+	}
+}
+
+// Tri returns the vertices of the i-th triangle.
+func (m *Mesh) Tri(i int) (*geom.Vec3, *geom.Vec3, *geom.Vec3) {
+	t := m.Tris[i]
+	return &m.Verts[t[0]], &m.Verts[t[1]], &m.Verts[t[2]]
+}
+
+// Bounds returns the minimum and maximum corner of the smallest axis-aligned
+// box containing all vertices. Both are zero for a mesh without vertices.
+func (m *Mesh) Bounds() (*geom.Vec3, *geom.Vec3) {
+	var min, max geom.Vec3
+	for i, v := range m.Verts {
+		for k := 0; k < 3; k++ {
+			if i == 0 || v[k] < min[k] {
+				min[k] = v[k]
+			}
+			if i == 0 || v[k] > max[k] {
+				max[k] = v[k]
+			}
+		}
+	}
+	return &min, &max
+}
+
+// triNormal returns the unit normal of the triangle with the given vertices.
+// Seen from the front the vertices are in counter-clockwise order.
+func triNormal(p0, p1, p2 *geom.Vec3) *geom.Vec3 {
+	e1 := *p1
+	e1.Sub(p0)
+	e2 := *p2
+	e2.Sub(p0)
+	n := geom.Cross(&e1, &e2)
+	n.Norm()
+	return n
+}
+
+// vertNormals returns a unit normal for each vertex as the average of the
+// normals of the triangles sharing it, weighted by the triangle's angle at the
+// vertex. The weighting makes the result independent of how faces are split
+// into triangles.
+func vertNormals(verts []geom.Vec3, tris [][3]int) []geom.Vec3 {
+	ns := make([]geom.Vec3, len(verts))
+	for _, t := range tris {
+		n := triNormal(&verts[t[0]], &verts[t[1]], &verts[t[2]])
+		for k := 0; k < 3; k++ {
+			p := verts[t[k]]
+			a := verts[t[(k+1)%3]]
+			a.Sub(&p)
+			b := verts[t[(k+2)%3]]
+			b.Sub(&p)
+			l := a.Len() * b.Len()
+			if l == 0 {
+				continue
+			}
+			w := *n
+			w.Scale(math.Acos(math.Max(-1, math.Min(1, geom.Dot(&a, &b)/l))))
+			ns[t[k]].Add(&w)
+		}
+	}
+	for i := range ns {
+		ns[i].Norm()
+	}
+	return ns
+}
+
+// Object places a mesh with a material in a scene.
+type Object struct {
+
+	// Mesh is the shape of the object.
+	Mesh *Mesh
+
+	// Material of the whole mesh. If nil a light gray diffuse material is used.
+	Material *Material
+
+	// Transf transforms from object coordinates to world coordinates.
+	Transf geom.Mat4
+}
+
+// NewObject returns a new object with the given mesh and material placed at
+// the origin.
+func NewObject(m *Mesh, mat *Material) *Object {
+	return &Object{Mesh: m, Material: mat, Transf: *geom.IdentMat()}
+}
+
+// worldVerts returns the mesh's vertices in world coordinates.
+func (o *Object) worldVerts() []geom.Vec3 {
+	vs := make([]geom.Vec3, len(o.Mesh.Verts))
+	for i, v := range o.Mesh.Verts {
+		w := o.Transf.Transf(geom.NewVec4(v[0], v[1], v[2]))
+		w.Norm()
+		vs[i] = geom.Vec3{w[0], w[1], w[2]}
+	}
+	return vs
+}
+
+// corners returns the corners of the mesh's bounds in world coordinates.
+// Corner i has bit 0, 1 and 2 set if it is at the maximum along x, y and z in
+// object coordinates.
+func (o *Object) corners() [8]geom.Vec3 {
+	min, max := o.Mesh.Bounds()
+	var cs [8]geom.Vec3
+	for i := range cs {
+		v := *min
+		for k := uint(0); k < 3; k++ {
+			if i&(1<<k) != 0 {
+				v[k] = max[k]
+			}
+		}
+		w := o.Transf.Transf(geom.NewVec4(v[0], v[1], v[2]))
+		w.Norm()
+		cs[i] = geom.Vec3{w[0], w[1], w[2]}
+	}
+	return cs
+}
+
+// material returns the object's material or the default one.
+func (o *Object) material() *Material {
+	if o.Material == nil {
+		return defMat
+	}
+	return o.Material
+}
+
+// Light is a point light that shines equally in all directions. The light
+// reaching a surface falls off with the squared distance.
+type Light struct {
+
+	// Pos is the position in world coordinates.
+	Pos geom.Vec3
+
+	// Color is the intensity of the light for red, green and blue.
+	Color geom.Vec3
+}
+
+// Scene is a set of objects and lights.
+type Scene struct {
+
+	// Objects to render
+	Objects []*Object
+
+	// Lights illuminating the objects
+	Lights []*Light
+
+	// Ambient light reaching every surface from all directions
+	Ambient geom.Vec3
+}
+
+// NewScene returns a new empty scene.
+func NewScene() *Scene {
+	return &Scene{}
+}
+
+// Add adds objects to the scene.
+func (s *Scene) Add(o ...*Object) {
+	s.Objects = append(s.Objects, o...)
+}
+
+// AddLight adds lights to the scene.
+func (s *Scene) AddLight(l ...*Light) {
+	s.Lights = append(s.Lights, l...)
+}
+
+// shadeFlat returns the color of a triangle with the given world coordinates
+// and material as seen from eye. The normal is flipped towards the eye, so both
+// sides of a triangle are lit. Light is diffusely reflected (Lambert).
+func (s *Scene) shadeFlat(p0, p1, p2 *geom.Vec3, mat *Material, eye *geom.Vec3) geom.Vec3 {
+	n := triNormal(p0, p1, p2)
+	ctr := *p0
+	ctr.Add(p1)
+	ctr.Add(p2)
+	ctr.Scale(1.0 / 3)
+	v := *eye
+	v.Sub(&ctr)
+	if geom.Dot(n, &v) < 0 {
+		n.Neg()
+	}
+	irr := s.Ambient
+	for _, l := range s.Lights {
+		d := l.Pos
+		d.Sub(&ctr)
+		d2 := geom.Dot(&d, &d)
+		if d2 == 0 {
+			continue
+		}
+		k := geom.Dot(n, &d) / (d2 * math.Sqrt(d2))
+		if k <= 0 {
+			continue
+		}
+		c := l.Color
+		c.Scale(k)
+		irr.Add(&c)
+	}
+	col := mat.Emission
+	for i := 0; i < 3; i++ {
+		col[i] += mat.Color[i] * irr[i]
+	}
+	return col
+}
@@ -0,0 +1,420 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/image"
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Distance to move the origins of secondary rays off surfaces, so they don't
+// hit the surface they start on again
+const rayOffset = 1e-6
+
+// emitter is a triangle with emissive material in world coordinates.
+type emitter struct {
+	p0, p1, p2 geom.Vec3
+	n          geom.Vec3
+	emission   geom.Vec3
+}
+
+// PathTracer renders a scene with Monte Carlo path tracing. Every sample
+// follows a random path of light bouncing between surfaces from the camera
+// until it leaves the scene, so indirect light, soft shadows, reflections and
+// refractions appear naturally. Samples are accumulated over time and the
+// average converges to the exact result, noise decreases with the square root
+// of the number of samples.
+//
+// Primary rays are generated the same way the rasterizer projects, so both
+// show the same view of a scene. Lights, the scene's ambient light and
+// emission light diffuse surfaces the same as in the rasterizer directly.
+// Emissive triangles are area lights. The ambient light comes from all
+// directions where rays leave the scene.
+type PathTracer struct {
+
+	// MaxDepth is the maximum number of surfaces a path bounces off.
+	MaxDepth int
+
+	// Seed determines the random numbers. Rendering the same scene with the
+	// same seed gives the same image.
+	Seed int64
+
+	// Workers is the number of goroutines sampling rows of pixels in
+	// parallel, GOMAXPROCS if below 1. Random numbers are drawn per row, so
+	// the samples don't depend on it.
+	Workers int
+
+	scene    *Scene
+	cam      *Camera
+	accel    *Accel
+	emitters []emitter
+
+	// Summed areas of the emitters to choose them by their area
+	areas []float64
+
+	// Summed up radiance and number of samples per pixel
+	sum     []float64
+	samples int
+
+	result *image.FloatImage
+}
+
+// NewPathTracer returns a new path tracer rendering scene s as seen by camera
+// c in an image of w times h pixels, with a maximum depth of 8.
+func NewPathTracer(s *Scene, c *Camera, w, h int) *PathTracer {
+	p := &PathTracer{
+		MaxDepth: 8,
+		scene:    s,
+		cam:      c,
+		accel:    NewAccel(s),
+		sum:      make([]float64, 3*w*h),
+		result:   image.NewFloatImage(w, h),
+	}
+	p.findEmitters()
+	return p
+}
+
+// findEmitters collects all emissive triangles.
+func (p *PathTracer) findEmitters() {
+	p.emitters = nil
+	p.areas = nil
+	total := 0.0
+	for _, o := range p.scene.Objects {
+		mat := o.material()
+		if mat.Emission == (geom.Vec3{}) {
+			continue
+		}
+		vs := o.worldVerts()
+		for _, t := range o.Mesh.Tris {
+			e := emitter{p0: vs[t[0]], p1: vs[t[1]], p2: vs[t[2]]}
+			e1 := e.p1
+			e1.Sub(&e.p0)
+			e2 := e.p2
+			e2.Sub(&e.p0)
+			c := geom.Cross(&e1, &e2)
+			a := c.Len() / 2
+			if a == 0 {
+				continue
+			}
+			c.Norm()
+			e.n = *c
+			e.emission = mat.Emission
+			total += a
+			p.emitters = append(p.emitters, e)
+			p.areas = append(p.areas, total)
+		}
+	}
+}
+
+// Reset discards all samples, e.g. after the camera or objects moved. Objects
+// must not be added or removed, which needs a new path tracer.
+func (p *PathTracer) Reset() {
+	for i := range p.sum {
+		p.sum[i] = 0
+	}
+	p.samples = 0
+	p.accel.Refit()
+	p.findEmitters()
+}
+
+// Samples returns the number of samples per pixel taken so far.
+func (p *PathTracer) Samples() int {
+	return p.samples
+}
+
+// Sample takes one more sample for every pixel at a random position within
+// the pixel. Rows are traced in parallel, see Workers.
+func (p *PathTracer) Sample() {
+	w := p.result.Width
+	h := p.result.Height
+	gen := p.cam.rayGen(w, h)
+	rows := make(chan int, h)
+	for y := 0; y < h; y++ {
+		rows <- y
+	}
+	close(rows)
+	var wg sync.WaitGroup
+	for n := 0; n < numWorkers(p.Workers); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				// Random numbers depend only on the seed, sample and row
+				rng := rand.New(rand.NewSource(p.Seed + int64(p.samples*h+y)))
+				for x := 0; x < w; x++ {
+					r := gen.ray(float64(x)+rng.Float64(), float64(y)+rng.Float64())
+					l := p.radiance(r, rng)
+					if math.IsNaN(l[0] + l[1] + l[2]) {
+						continue
+					}
+					i := 3 * (y*w + x)
+					for k := 0; k < 3; k++ {
+						p.sum[i+k] += l[k]
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	p.samples++
+}
+
+// Result returns the average of the samples so far as an opaque image. The
+// image is reused by later calls.
+func (p *PathTracer) Result() *image.FloatImage {
+	inv := 1.0
+	if p.samples > 0 {
+		inv /= float64(p.samples)
+	}
+	for i := 0; i < len(p.sum)/3; i++ {
+		for k := 0; k < 3; k++ {
+			p.result.Pix[4*i+k] = float32(p.sum[3*i+k] * inv)
+		}
+		p.result.Pix[4*i+3] = 1
+	}
+	return p.result
+}
+
+// Image returns a new image of the result tone mapped with t.
+func (p *PathTracer) Image(t image.ToneMap) *image.Image {
+	return p.Result().Resolve(t)
+}
+
+// hitNormal returns the unit normal of the triangle hit, turned towards the
+// side the ray comes from, and whether the ray hits the front side.
+func hitNormal(h *Hit, r *geom.Ray) (*geom.Vec3, bool) {
+	o := h.Object
+	t := o.Mesh.Tris[h.Tri]
+	var ps [3]geom.Vec3
+	for k := range ps {
+		v := o.Mesh.Verts[t[k]]
+		w := o.Transf.Transf(geom.NewVec4(v[0], v[1], v[2]))
+		w.Norm()
+		ps[k] = geom.Vec3{w[0], w[1], w[2]}
+	}
+	n := triNormal(&ps[0], &ps[1], &ps[2])
+	front := geom.Dot(n, &r.Dir) < 0
+	if !front {
+		n.Neg()
+	}
+	return n, front
+}
+
+// offset returns point p moved off the surface with normal n to the side
+// direction d points to.
+func offset(p, n, d *geom.Vec3) geom.Vec3 {
+	o := *n
+	o.Scale(rayOffset * math.Max(1, p.Len()))
+	if geom.Dot(n, d) < 0 {
+		o.Neg()
+	}
+	o.Add(p)
+	return o
+}
+
+// visible returns true if nothing is between points a and b.
+func (p *PathTracer) visible(a, b *geom.Vec3) bool {
+	r := &geom.Ray{Orig: *a, Dir: *b}
+	r.Dir.Sub(a)
+	h := p.accel.Pick(r)
+	// The direction isn't normalized, b is at distance 1
+	return h == nil || h.Dist >= 1-rayOffset
+}
+
+// direct returns the light arriving at point x with normal n directly from
+// lights and emitters, scaled like the irradiance in the rasterizer.
+func (p *PathTracer) direct(x, n *geom.Vec3, rng *rand.Rand) geom.Vec3 {
+	var l geom.Vec3
+	o := offset(x, n, n)
+	for _, light := range p.scene.Lights {
+		d := light.Pos
+		d.Sub(x)
+		d2 := geom.Dot(&d, &d)
+		if d2 == 0 {
+			continue
+		}
+		k := geom.Dot(n, &d) / (d2 * math.Sqrt(d2))
+		if k <= 0 || !p.visible(&o, &light.Pos) {
+			continue
+		}
+		c := light.Color
+		c.Scale(k)
+		l.Add(&c)
+	}
+	if len(p.emitters) == 0 {
+		return l
+	}
+	// One emitter chosen by area and a uniformly random point on it
+	total := p.areas[len(p.areas)-1]
+	e := &p.emitters[sort.SearchFloat64s(p.areas, rng.Float64()*total)]
+	u := math.Sqrt(rng.Float64())
+	v := rng.Float64()
+	q := geom.Vec3{}
+	for k := 0; k < 3; k++ {
+		q[k] = (1-u)*e.p0[k] + u*(1-v)*e.p1[k] + u*v*e.p2[k]
+	}
+	d := q
+	d.Sub(x)
+	d2 := geom.Dot(&d, &d)
+	if d2 == 0 {
+		return l
+	}
+	dl := math.Sqrt(d2)
+	cs := geom.Dot(n, &d) / dl
+	cl := math.Abs(geom.Dot(&e.n, &d)) / dl
+	if cs <= 0 || cl <= 0 || !p.visible(&o, &q) {
+		return l
+	}
+	c := e.emission
+	c.Scale(cs * cl / d2 * total / math.Pi)
+	l.Add(&c)
+	return l
+}
+
+// cosineDir returns a random unit direction around normal n with probability
+// proportional to the cosine of the angle to n.
+func cosineDir(n *geom.Vec3, rng *rand.Rand) geom.Vec3 {
+	// Orthonormal basis around n
+	a := geom.Vec3{1, 0, 0}
+	if math.Abs(n[0]) > 0.9 {
+		a = geom.Vec3{0, 1, 0}
+	}
+	u := geom.Cross(&a, n)
+	u.Norm()
+	v := geom.Cross(n, u)
+	r := math.Sqrt(rng.Float64())
+	phi := 2 * math.Pi * rng.Float64()
+	x := r * math.Cos(phi)
+	y := r * math.Sin(phi)
+	z := math.Sqrt(math.Max(0, 1-r*r))
+	var d geom.Vec3
+	for k := 0; k < 3; k++ {
+		d[k] = x*u[k] + y*v[k] + z*n[k]
+	}
+	return d
+}
+
+// sphereDir returns a random point inside the unit sphere.
+func sphereDir(rng *rand.Rand) geom.Vec3 {
+	for {
+		d := geom.Vec3{2*rng.Float64() - 1, 2*rng.Float64() - 1, 2*rng.Float64() - 1}
+		if geom.Dot(&d, &d) <= 1 {
+			return d
+		}
+	}
+}
+
+// mirror returns direction d reflected at a surface with normal n.
+func mirror(d, n *geom.Vec3) geom.Vec3 {
+	r := *n
+	r.Scale(-2 * geom.Dot(d, n))
+	r.Add(d)
+	return r
+}
+
+// refract returns unit direction d refracted at a surface with normal n
+// pointing against d, where eta is the ratio of the indices of refraction
+// before and after the surface. It returns false for total internal
+// reflection.
+func refract(d, n *geom.Vec3, eta float64) (geom.Vec3, bool) {
+	c := -geom.Dot(d, n)
+	k := 1 - eta*eta*(1-c*c)
+	if k < 0 {
+		return geom.Vec3{}, false
+	}
+	r := *d
+	r.Scale(eta)
+	t := *n
+	t.Scale(eta*c - math.Sqrt(k))
+	r.Add(&t)
+	return r, true
+}
+
+// schlick returns the fraction of light reflected at a dielectric surface with
+// Schlick's approximation of the Fresnel equations. Cos is the cosine of the
+// angle of incidence on the side with the lower index of refraction.
+func schlick(cos, ior float64) float64 {
+	r0 := (1 - ior) / (1 + ior)
+	r0 *= r0
+	return r0 + (1-r0)*math.Pow(1-cos, 5)
+}
+
+// radiance returns the light arriving along ray r traced backwards from the
+// camera.
+func (p *PathTracer) radiance(r *geom.Ray, rng *rand.Rand) geom.Vec3 {
+	var l geom.Vec3
+	tp := geom.Vec3{1, 1, 1}
+	// Emission is added when hit directly or after specular bounces, it is
+	// sampled directly after diffuse bounces
+	specular := true
+	for depth := 0; depth < p.MaxDepth; depth++ {
+		h := p.accel.Pick(r)
+		if h == nil {
+			for k := 0; k < 3; k++ {
+				l[k] += tp[k] * p.scene.Ambient[k]
+			}
+			break
+		}
+		mat := h.Object.material()
+		n, front := hitNormal(h, r)
+		if specular {
+			for k := 0; k < 3; k++ {
+				l[k] += tp[k] * mat.Emission[k]
+			}
+		}
+		var d geom.Vec3
+		switch mat.Surface {
+		case Metal:
+			d = mirror(&r.Dir, n)
+			s := sphereDir(rng)
+			s.Scale(mat.Roughness)
+			d.Add(&s)
+			if geom.Dot(&d, n) <= 0 {
+				return l
+			}
+			specular = true
+		case Dielectric:
+			eta := 1 / mat.Ior
+			if !front {
+				eta = mat.Ior
+			}
+			cos := -geom.Dot(&r.Dir, n)
+			t, ok := refract(&r.Dir, n, eta)
+			if ok && !front {
+				// Angle on the side of the lower index of refraction
+				cos = -geom.Dot(&t, n)
+			}
+			if !ok || rng.Float64() < schlick(cos, mat.Ior) {
+				d = mirror(&r.Dir, n)
+			} else {
+				d = t
+			}
+			specular = true
+		default:
+			dl := p.direct(&h.Pos, n, rng)
+			for k := 0; k < 3; k++ {
+				l[k] += tp[k] * mat.Color[k] * dl[k]
+			}
+			d = cosineDir(n, rng)
+			specular = false
+		}
+		for k := 0; k < 3; k++ {
+			tp[k] *= mat.Color[k]
+		}
+		// Russian roulette ends dark paths early without bias
+		if depth >= 3 {
+			q := math.Max(tp[0], math.Max(tp[1], tp[2]))
+			if q < 1 {
+				if rng.Float64() >= q {
+					break
+				}
+				tp.Scale(1 / q)
+			}
+		}
+		d.Norm()
+		r = &geom.Ray{Orig: offset(&h.Pos, n, &d), Dir: d}
+	}
+	return l
+}
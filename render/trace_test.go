@@ -0,0 +1,133 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/image"
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+	"testing"
+)
+
+// mean returns the average color of all pixels.
+func mean(f *image.FloatImage) geom.Vec3 {
+	var m geom.Vec3
+	for i := 0; i < len(f.Pix); i += 4 {
+		for k := 0; k < 3; k++ {
+			m[k] += float64(f.Pix[i+k])
+		}
+	}
+	m.Scale(4 / float64(len(f.Pix)))
+	return m
+}
+
+// wall returns an object with a square of size 40 facing the camera at
+// distance d with material m.
+func wall(d float64, m *Material) *Object {
+	o := quad(d, 0, 0, 0)
+	o.Transf = *ScaleTransf(&geom.Vec3{20, 20, 1})
+	o.Material = m
+	return o
+}
+
+var tracetests = []struct {
+	objs    []*Object
+	ambient float64
+	should  float64
+	tol     float64
+}{
+	// Ambient light reflected by a diffuse surface like in the rasterizer
+	{[]*Object{wall(2, NewMaterial(0.5, 0.5, 0.5))}, 1, 0.5, 1e-6},
+	// Emission seen directly
+	{[]*Object{wall(2, &Material{Emission: geom.Vec3{3, 3, 3}})}, 0, 3, 1e-6},
+	// Perfect mirror reflects the ambient light from behind the camera
+	{[]*Object{wall(2, NewMetal(0.8, 0.8, 0.8, 0))}, 1, 0.8, 1e-6},
+	// Glass in front of a light reflects 4% at normal incidence
+	{[]*Object{
+		wall(2, NewDielectric(1.5)),
+		wall(4, &Material{Emission: geom.Vec3{1, 1, 1}}),
+	}, 0, 0.96, 0.02},
+}
+
+func TestPathTracer(t *testing.T) {
+	for _, test := range tracetests {
+		s := NewScene()
+		s.Add(test.objs...)
+		s.Ambient = geom.Vec3{test.ambient, test.ambient, test.ambient}
+		p := NewPathTracer(s, NewDefCam(), 8, 8)
+		for n := 0; n < 50; n++ {
+			p.Sample()
+		}
+		if n := p.Samples(); n != 50 {
+			t.Errorf("expected '%v' but got '%v'", 50, n)
+		}
+		if m := mean(p.Result()); math.Abs(m[0]-test.should) > test.tol {
+			t.Errorf("expected '%v' but got '%v'", test.should, m[0])
+		}
+	}
+}
+
+func TestPathTracerLights(t *testing.T) {
+	// Diffuse floor lit by a point light and by an emissive square of the same
+	// power far above, which is about the same as a point light
+	for _, area := range []bool{false, true} {
+		s := NewScene()
+		s.Add(wall(2, NewMaterial(1, 1, 1)))
+		if area {
+			l := quad(0, 0, 0, 0)
+			l.Transf = *TranslTransf(&geom.Vec3{0, 0, 18})
+			l.Transf.Mul(ScaleTransf(&geom.Vec3{0.1, 0.1, 1}))
+			// Emission times area over pi is the color of a point light
+			l.Material = &Material{Emission: geom.Vec3{100 * math.Pi, 0, 0}}
+			s.Add(l)
+		} else {
+			s.AddLight(&Light{Pos: geom.Vec3{0, 0, 18}, Color: geom.Vec3{4, 0, 0}})
+		}
+		p := NewPathTracer(s, NewDefCam(), 4, 4)
+		p.MaxDepth = 1
+		for n := 0; n < 20; n++ {
+			p.Sample()
+		}
+		// Intensity 4 at distance 20
+		if m := mean(p.Result()); math.Abs(m[0]-0.01) > 0.0005 {
+			t.Errorf("expected '%v' but got '%v'", 0.01, m[0])
+		}
+	}
+}
+
+func TestPathTracerSeed(t *testing.T) {
+	s := NewScene()
+	s.Add(wall(2, NewMaterial(0.5, 0.5, 0.5)))
+	s.AddLight(&Light{Pos: geom.Vec3{1, 1, 0}, Color: geom.Vec3{1, 1, 1}})
+	imgs := make([]*image.Image, 2)
+	for i := range imgs {
+		p := NewPathTracer(s, NewDefCam(), 8, 8)
+		// Same with any number of workers
+		p.Workers = 1 + 2*i
+		p.Sample()
+		p.Sample()
+		imgs[i] = p.Image(image.Clamp)
+	}
+	if string(imgs[0].Rgba.Pix) != string(imgs[1].Rgba.Pix) {
+		t.Errorf("expected same image for same seed")
+	}
+}
+
+func TestRefract(t *testing.T) {
+	// 45 degrees into glass and back out
+	d := geom.Vec3{1, -1, 0}
+	d.Norm()
+	n := geom.Vec3{0, 1, 0}
+	r, ok := refract(&d, &n, 1/1.5)
+	if !ok || math.Abs(r[0]-math.Sqrt(0.5)/1.5) > 1e-9 || math.Abs(r.Len()-1) > 1e-9 {
+		t.Errorf("expected refraction but got '%v'", r)
+	}
+	// Total internal reflection at a flat angle from inside
+	n.Neg()
+	d = geom.Vec3{1, 0.3, 0}
+	d.Norm()
+	if _, ok := refract(&d, &n, 1.5); ok {
+		t.Errorf("expected total internal reflection")
+	}
+	if f := schlick(1, 1.5); math.Abs(f-0.04) > 1e-9 {
+		t.Errorf("expected '%v' but got '%v'", 0.04, f)
+	}
+}
@@ -0,0 +1,257 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+	"sync"
+)
+
+// RayTracer renders a scene with Whitted-style ray tracing. For every pixel a
+// ray is cast from the camera. Where it hits a surface, shadow rays are cast
+// to the lights, and reflection and refraction rays are cast recursively up to
+// a fixed depth. Unlike path tracing there is no randomness, so the same scene
+// always gives the same image, and no indirect light except for reflections
+// and refractions.
+//
+// Diffuse surfaces are lit like in the rasterizer, but at every point instead
+// of once per triangle, so both can be compared. Translucent diffuse surfaces
+// are blended with what is behind them like in the rasterizer, where nothing
+// is behind them with the color already in the framebuffer. Metal surfaces are
+// perfect mirrors, their roughness is ignored. Shadow rays pass through
+// translucent and dielectric surfaces without refraction, attenuated by their
+// transparency and color.
+type RayTracer struct {
+
+	// MaxDepth is the maximum number of surfaces a ray is reflected or
+	// refracted at. Deeper rays are black.
+	MaxDepth int
+
+	// Workers is the number of goroutines tracing rows of pixels in
+	// parallel, GOMAXPROCS if below 1. Tracing has no randomness and every
+	// pixel is written by one goroutine only, so the image is always the
+	// same.
+	Workers int
+}
+
+// NewRayTracer returns a new ray tracer with a maximum depth of 5.
+func NewRayTracer() *RayTracer {
+	return &RayTracer{MaxDepth: 5}
+}
+
+// Render draws all objects of the scene as seen by the camera into the
+// framebuffer like Rasterize. Pixels where nothing is hit are left as they
+// are. With anti-aliasing a ray is cast through every sample position. The
+// depth and, if enabled, the id of the surface hit at the pixel center are
+// stored too. Rows are traced in parallel, see Workers.
+func (t *RayTracer) Render(s *Scene, c *Camera, fb *Framebuffer) {
+	w := fb.Width()
+	h := fb.Height()
+	gen := c.rayGen(w, h)
+	tr := &tracing{t, s, NewAccel(s)}
+	_, _, z := c.CamAxes()
+	ids := make(map[*Object]int)
+	for i, o := range s.Objects {
+		ids[o] = i + 1
+	}
+	samples := [][2]float64{{0.5, 0.5}}
+	if fb.aa != nil {
+		samples = fb.aa.Samples
+	}
+	rows := make(chan int, h)
+	for y := 0; y < h; y++ {
+		rows <- y
+	}
+	close(rows)
+	var wg sync.WaitGroup
+	for n := 0; n < numWorkers(t.Workers); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				for x := 0; x < w; x++ {
+					i := y*w + x
+					var sum [3]float64
+					cov := 0.0
+					for _, sp := range samples {
+						r := gen.ray(float64(x)+sp[0], float64(y)+sp[1])
+						l, a := tr.radiance(r, 0)
+						cov += a
+						for k := 0; k < 3; k++ {
+							sum[k] += l[k]
+						}
+					}
+					if cov == 0 {
+						continue
+					}
+					// Drawn over the color there like in the rasterizer
+					pix := fb.Color.Pix[4*i : 4*i+4]
+					n := float64(len(samples))
+					a := cov / n
+					for k := 0; k < 3; k++ {
+						pix[k] = float32(sum[k]/n + (1-a)*float64(pix[k]))
+					}
+					pix[3] = float32(a + (1-a)*float64(pix[3]))
+					// Ids include translucent surfaces, depth doesn't
+					r := gen.ray(float64(x)+0.5, float64(y)+0.5)
+					first := true
+					for hit := tr.accel.Pick(r); hit != nil; hit = tr.accel.Pick(r) {
+						if fb.Ids != nil && first {
+							fb.Ids[i] = Id{ids[hit.Object], hit.Tri}
+							first = false
+						}
+						if hit.Object.material().Transparency == 0 {
+							d := hit.Pos
+							d.Sub(&c.Eye)
+							fb.Depth[i] = -1 / geom.Dot(&d, z)
+							break
+						}
+						n, _ := hitNormal(hit, r)
+						r.Orig = offset(&hit.Pos, n, &r.Dir)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// tracing is the state while ray tracing a scene.
+type tracing struct {
+	t     *RayTracer
+	scene *Scene
+	accel *Accel
+}
+
+// transmit returns the fraction of light passing from point a to point b.
+func (tr *tracing) transmit(a, b *geom.Vec3) geom.Vec3 {
+	f := geom.Vec3{1, 1, 1}
+	r := &geom.Ray{Orig: *a, Dir: *b}
+	r.Dir.Sub(a)
+	for {
+		h := tr.accel.Pick(r)
+		// The direction isn't normalized, b is at distance 1
+		if h == nil || h.Dist >= 1-rayOffset {
+			return f
+		}
+		mat := h.Object.material()
+		switch {
+		case mat.Surface == Dielectric:
+			for k := 0; k < 3; k++ {
+				f[k] *= mat.Color[k]
+			}
+		case mat.Surface == Diffuse && mat.Transparency > 0:
+			f.Scale(mat.Transparency)
+		default:
+			return geom.Vec3{}
+		}
+		if f == (geom.Vec3{}) {
+			return f
+		}
+		// Continue behind the surface, b stays at distance 1
+		n, _ := hitNormal(h, r)
+		o := offset(&h.Pos, n, &r.Dir)
+		r.Orig = o
+		r.Dir = *b
+		r.Dir.Sub(&o)
+	}
+}
+
+// shade returns the light reflected by a diffuse surface at point x with
+// normal n turned towards the viewer, lit like in the rasterizer.
+func (tr *tracing) shade(x, n *geom.Vec3, mat *Material) geom.Vec3 {
+	irr := tr.scene.Ambient
+	o := offset(x, n, n)
+	for _, l := range tr.scene.Lights {
+		d := l.Pos
+		d.Sub(x)
+		d2 := geom.Dot(&d, &d)
+		if d2 == 0 {
+			continue
+		}
+		k := geom.Dot(n, &d) / (d2 * math.Sqrt(d2))
+		if k <= 0 {
+			continue
+		}
+		f := tr.transmit(&o, &l.Pos)
+		for i := 0; i < 3; i++ {
+			irr[i] += l.Color[i] * k * f[i]
+		}
+	}
+	col := mat.Emission
+	for i := 0; i < 3; i++ {
+		col[i] += mat.Color[i] * irr[i]
+	}
+	return col
+}
+
+// radiance returns the light arriving along ray r at the given depth of
+// recursion, and the fraction of it that covers what is behind the scene. The
+// light is premultiplied by that fraction. It is 0 if nothing is hit, and less
+// than 1 if only translucent diffuse surfaces are hit before nothing.
+func (tr *tracing) radiance(r *geom.Ray, depth int) (geom.Vec3, float64) {
+	h := tr.accel.Pick(r)
+	if h == nil {
+		return geom.Vec3{}, 0
+	}
+	mat := h.Object.material()
+	n, front := hitNormal(h, r)
+	// through returns the light arriving along direction d from the hit and
+	// its coverage
+	through := func(d geom.Vec3) (geom.Vec3, float64) {
+		if depth+1 >= tr.t.MaxDepth {
+			return geom.Vec3{}, 1
+		}
+		d.Norm()
+		return tr.radiance(&geom.Ray{Orig: offset(&h.Pos, n, &d), Dir: d}, depth+1)
+	}
+	// follow is like through, but reflected and refracted rays that hit
+	// nothing see the ambient light
+	follow := func(d geom.Vec3) geom.Vec3 {
+		l, a := through(d)
+		amb := tr.scene.Ambient
+		amb.Scale(1 - a)
+		l.Add(&amb)
+		return l
+	}
+	var l geom.Vec3
+	switch mat.Surface {
+	case Metal:
+		l = follow(mirror(&r.Dir, n))
+		for k := 0; k < 3; k++ {
+			l[k] *= mat.Color[k]
+		}
+		l.Add(&mat.Emission)
+	case Dielectric:
+		eta := 1 / mat.Ior
+		if !front {
+			eta = mat.Ior
+		}
+		cos := -geom.Dot(&r.Dir, n)
+		t, ok := refract(&r.Dir, n, eta)
+		if ok && !front {
+			cos = -geom.Dot(&t, n)
+		}
+		l = follow(mirror(&r.Dir, n))
+		if ok {
+			f := schlick(cos, mat.Ior)
+			l.Scale(f)
+			lt := follow(t)
+			lt.Scale(1 - f)
+			l.Add(&lt)
+		}
+		for k := 0; k < 3; k++ {
+			l[k] *= mat.Color[k]
+		}
+		l.Add(&mat.Emission)
+	default:
+		l = tr.shade(&h.Pos, n, mat)
+		if t := mat.Transparency; t > 0 {
+			l.Scale(1 - t)
+			b, a := through(r.Dir)
+			b.Scale(t)
+			l.Add(&b)
+			return l, 1 - t + t*a
+		}
+	}
+	return l, 1
+}
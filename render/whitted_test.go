@@ -0,0 +1,159 @@
+package render
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// sameColor returns true if colors a and b differ by at most 1e-6.
+func sameColor(a, b [4]float32) bool {
+	for k := range a {
+		if math.Abs(float64(a[k]-b[k])) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRayTraceMatchesRaster(t *testing.T) {
+	// Emission and ambient light are the same everywhere on a triangle
+	objs := []*Object{
+		quad(6, 0, 0, 1), glass(4, 1, 0, 0, 0.5), glass(2, 0, 1, 0, 0.5),
+		wall(8, &Material{Color: geom.Vec3{0.5, 0.2, 0.1}}),
+	}
+	s := NewScene()
+	s.Add(objs...)
+	s.Ambient = geom.Vec3{0.4, 0.4, 0.4}
+	// Slightly moved, so no edge goes exactly through a sample position
+	c := NewDefCam()
+	c.Eye = geom.Vec3{0.013, 0.011, 0}
+	c.At = geom.Vec3{0.013, 0.011, -1}
+	for _, aa := range []*Aa{nil, NewMsaa(RotatedGrid())} {
+		raster := NewFramebufferAa(10, 10, aa)
+		raster.SetIds(true)
+		Rasterize(s, c, raster)
+		traced := NewFramebufferAa(10, 10, aa)
+		traced.SetIds(true)
+		NewRayTracer().Render(s, c, traced)
+		for i := range raster.Depth {
+			x, y := i%10, i/10
+			if a, b := raster.Color.At(x, y), traced.Color.At(x, y); !sameColor(a, b) {
+				t.Errorf("expected '%v' but got '%v' at '%v'", a, b, [2]int{x, y})
+			}
+			if raster.Depth[i] != 0 && math.Abs(raster.Depth[i]-traced.Depth[i]) > 1e-9 {
+				t.Errorf("expected '%v' but got '%v'", raster.Depth[i], traced.Depth[i])
+			}
+			if raster.Ids[i] != traced.Ids[i] {
+				t.Errorf("expected '%v' but got '%v'", raster.Ids[i], traced.Ids[i])
+			}
+		}
+	}
+}
+
+func TestRayTraceTranslucentBackground(t *testing.T) {
+	// Nothing behind the translucent quads, they blend with the background
+	s := NewScene()
+	s.Add(quad(6, 0, 0, 1), glass(4, 1, 0, 0, 0.5), glass(2, 0, 1, 0, 0.5))
+	s.Ambient = geom.Vec3{0.4, 0.4, 0.4}
+	c := NewDefCam()
+	c.Eye = geom.Vec3{0.013, 0.011, 0}
+	c.At = geom.Vec3{0.013, 0.011, -1}
+	bg := &geom.Vec3{0.1, 0.2, 0.3}
+	raster := NewFramebuffer(10, 10)
+	raster.Clear(bg)
+	Rasterize(s, c, raster)
+	traced := NewFramebuffer(10, 10)
+	traced.Clear(bg)
+	NewRayTracer().Render(s, c, traced)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			if a, b := raster.Color.At(x, y), traced.Color.At(x, y); !sameColor(a, b) {
+				t.Errorf("expected '%v' but got '%v' at '%v'", a, b, [2]int{x, y})
+			}
+		}
+	}
+}
+
+func TestRayTraceParallel(t *testing.T) {
+	s, c := tiledScene(41.0 / 31)
+	var fbs [2]*Framebuffer
+	for k, workers := range []int{1, 4} {
+		fbs[k] = NewFramebuffer(41, 31)
+		fbs[k].SetIds(true)
+		r := NewRayTracer()
+		r.Workers = workers
+		r.Render(s, c, fbs[k])
+	}
+	if !reflect.DeepEqual(fbs[0].Color.Pix, fbs[1].Color.Pix) {
+		t.Errorf("expected same colors with and without workers")
+	}
+	if !reflect.DeepEqual(fbs[0].Depth, fbs[1].Depth) {
+		t.Errorf("expected same depth with and without workers")
+	}
+	if !reflect.DeepEqual(fbs[0].Ids, fbs[1].Ids) {
+		t.Errorf("expected same ids with and without workers")
+	}
+}
+
+var whittedtests = []struct {
+	objs   []*Object
+	should float32
+}{
+	// Perfect mirror reflects the ambient light from behind the camera
+	{[]*Object{wall(2, NewMetal(0.8, 0.8, 0.8, 0.5))}, 0.8},
+	// Glass in front of a light reflects 4% at normal incidence
+	{[]*Object{
+		wall(2, NewDielectric(1.5)),
+		wall(4, &Material{Emission: geom.Vec3{1, 1, 1}}),
+	}, 0.96 + 0.04},
+	// Two parallel mirrors reflect until the maximum depth
+	{[]*Object{
+		wall(2, NewMetal(0.5, 0.5, 0.5, 0)),
+		wall(-2, NewMetal(0.5, 0.5, 0.5, 0)),
+	}, 0},
+}
+
+func TestRayTrace(t *testing.T) {
+	for _, test := range whittedtests {
+		s := NewScene()
+		s.Add(test.objs...)
+		s.Ambient = geom.Vec3{1, 1, 1}
+		fb := NewFramebuffer(4, 4)
+		NewRayTracer().Render(s, NewDefCam(), fb)
+		if c := fb.Color.At(2, 2)[0]; math.Abs(float64(c-test.should)) > 1e-6 {
+			t.Errorf("expected '%v' but got '%v'", test.should, c)
+		}
+	}
+}
+
+func TestRayTraceShadow(t *testing.T) {
+	// Floor lit from the side, the shadow of a small square in front of it is
+	// seen at pixel (3,5) beside the square
+	s := NewScene()
+	floor := wall(4, NewMaterial(1, 1, 1))
+	s.Add(floor)
+	s.AddLight(&Light{Pos: geom.Vec3{1, 0, 0}, Color: geom.Vec3{100, 100, 100}})
+	fb := NewFramebuffer(10, 10)
+	NewRayTracer().Render(s, NewDefCam(), fb)
+	lit := fb.Color.At(3, 5)[0]
+	blocker := quad(2, 0, 0, 0)
+	blocker.Transf = *ScaleTransf(&geom.Vec3{0.25, 0.25, 1})
+	for _, test := range []struct {
+		mat    *Material
+		should float32
+	}{
+		{NewMaterial(0, 0, 0), 0},
+		{NewDielectric(1.5), lit},
+		{&Material{Transparency: 0.25}, lit * 0.25},
+	} {
+		blocker.Material = test.mat
+		s.Objects = []*Object{floor, blocker}
+		fb := NewFramebuffer(10, 10)
+		NewRayTracer().Render(s, NewDefCam(), fb)
+		if c := fb.Color.At(3, 5)[0]; math.Abs(float64(c-test.should)) > 1e-4 {
+			t.Errorf("expected '%v' but got '%v'", test.should, c)
+		}
+	}
+}
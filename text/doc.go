@@ -0,0 +1,16 @@
+// Package text draws text on images and windows. Like package draw it works on
+// any draw.Image from the standard library, e.g. the RGBA image of an
+// image.Image or the canvas of a window.Window.
+//
+// Text is drawn with a face, which is a font at a certain size. The bitmap
+// face is embedded and needs nothing else, which makes it handy for debug
+// output like frame rates. For nicer text a TrueType font can be loaded from
+// a file with ParseFont and used as face at any size:
+//
+//   data, _ := ioutil.ReadFile("font.ttf")
+//   font, err := text.ParseFont(data)
+//   ...
+//   face := text.NewFontFace(font, 16)
+//   text.Draw(win.Canvas(), face, "Hello", 10, 10, color.White, text.Left)
+//
+package text
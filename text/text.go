@@ -0,0 +1,95 @@
+package text
+
+import (
+	"image/color"
+	stddraw "image/draw"
+	"strings"
+)
+
+// Metrics are the vertical measures of a face in pixels.
+type Metrics struct {
+
+	// Ascent is the distance from the baseline to the top of the highest
+	// glyphs.
+	Ascent float64
+
+	// Descent is the distance from the baseline down to the bottom of the
+	// lowest glyphs.
+	Descent float64
+
+	// Height is the distance between the baselines of two lines.
+	Height float64
+}
+
+// Face is a font at a certain size.
+type Face interface {
+
+	// Metrics returns the vertical metrics.
+	Metrics() Metrics
+
+	// Advance returns the horizontal distance from the start of the glyph for
+	// r to the start of the next one.
+	Advance(r rune) float64
+
+	// DrawGlyph draws the glyph for r with color c. The glyph's baseline
+	// starts at (x,y).
+	DrawGlyph(dst stddraw.Image, x, y float64, r rune, c color.Color)
+}
+
+// Align is the horizontal alignment of lines.
+type Align int
+
+const (
+	// Left aligns the left end of lines with x.
+	Left Align = iota
+
+	// Center aligns the middle of lines with x.
+	Center
+
+	// Right aligns the right end of lines with x.
+	Right
+)
+
+// Width returns the width of a single line of text.
+func Width(f Face, line string) float64 {
+	w := 0.0
+	for _, r := range line {
+		w += f.Advance(r)
+	}
+	return w
+}
+
+// Size returns the width of the longest line and the height of all lines of
+// text. Lines are separated by '\n'.
+func Size(f Face, s string) (float64, float64) {
+	lines := strings.Split(s, "\n")
+	w := 0.0
+	for _, l := range lines {
+		if lw := Width(f, l); lw > w {
+			w = lw
+		}
+	}
+	return w, float64(len(lines)) * f.Metrics().Height
+}
+
+// Draw draws text with face f and color c. Lines are separated by '\n' and
+// placed below each other with the line height of the face. The top of the
+// first line is at y, the lines are aligned horizontally at x as given by a.
+func Draw(dst stddraw.Image, f Face, s string, x, y float64, c color.Color, a Align) {
+	m := f.Metrics()
+	base := y + m.Ascent
+	for _, l := range strings.Split(s, "\n") {
+		lx := x
+		switch a {
+		case Center:
+			lx -= Width(f, l) / 2
+		case Right:
+			lx -= Width(f, l)
+		}
+		for _, r := range l {
+			f.DrawGlyph(dst, lx, base, r, c)
+			lx += f.Advance(r)
+		}
+		base += m.Height
+	}
+}
@@ -0,0 +1,91 @@
+package text
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// newRgba returns a new transparent image with the given size.
+func newRgba(w, h int) *image.RGBA {
+	return image.NewRGBA(image.Rect(0, 0, w, h))
+}
+
+// count returns the number of pixels with non-zero alpha.
+func count(img *image.RGBA) int {
+	n := 0
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// bounds returns the smallest rectangle containing all pixels with non-zero
+// alpha.
+func bounds(img *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y).A > 0 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+func TestBitmapGlyph(t *testing.T) {
+	img := newRgba(20, 20)
+	f := NewBitmapFace(2)
+	f.DrawGlyph(img, 2, 16, 'I', color.White)
+	// 11 pixels, each enlarged to 2x2
+	if n := count(img); n != 44 {
+		t.Errorf("expected '%v' pixels but got '%v'", 44, n)
+	}
+	r := image.Rect(4, 2, 10, 16)
+	if b := bounds(img); b != r {
+		t.Errorf("expected '%v' but got '%v'", r, b)
+	}
+}
+
+var sizetests = []struct {
+	s    string
+	w, h float64
+}{
+	{"", 0, 10},
+	{"abc", 18, 10},
+	{"a\nbcd\n", 18, 30},
+}
+
+func TestSize(t *testing.T) {
+	f := NewBitmapFace(1)
+	for _, test := range sizetests {
+		w, h := Size(f, test.s)
+		if w != test.w || h != test.h {
+			t.Errorf("expected '%v' but got '%v'", [2]float64{test.w, test.h}, [2]float64{w, h})
+		}
+	}
+}
+
+var aligntests = []struct {
+	a    Align
+	rect image.Rectangle
+}{
+	{Left, image.Rect(20, 5, 37, 22)},
+	{Center, image.Rect(11, 5, 28, 22)},
+	{Right, image.Rect(2, 5, 19, 22)},
+}
+
+func TestDrawAlign(t *testing.T) {
+	for _, test := range aligntests {
+		img := newRgba(40, 30)
+		// Longest line 18 pixels wide, second line 12
+		Draw(img, NewBitmapFace(1), "HHH\nHH", 20, 5, color.White, test.a)
+		if b := bounds(img); b != test.rect {
+			t.Errorf("expected '%v' but got '%v'", test.rect, b)
+		}
+	}
+}
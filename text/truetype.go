@@ -0,0 +1,509 @@
+package text
+
+import (
+	"errors"
+	"github.com/amsibamsi/three/draw"
+	"image/color"
+	stddraw "image/draw"
+)
+
+// Flags of points in simple glyphs
+const (
+	flagOnCurve = 0x01
+	flagXShort  = 0x02
+	flagYShort  = 0x04
+	flagRepeat  = 0x08
+	flagXSame   = 0x10
+	flagYSame   = 0x20
+)
+
+// Flags of components in compound glyphs
+const (
+	flagArgWords = 0x0001
+	flagArgXY    = 0x0002
+	flagScale    = 0x0008
+	flagMore     = 0x0020
+	flagXYScale  = 0x0040
+	flagTwoByTwo = 0x0080
+)
+
+// maxDepth limits the nesting of compound glyphs.
+const maxDepth = 8
+
+// reader reads big-endian numbers from a byte slice. Reading beyond the end
+// gives 0 and marks the reader as failed instead of panicking, so broken
+// fonts can be detected after reading.
+type reader struct {
+	b   []byte
+	off int
+	bad bool
+}
+
+// u8 reads an unsigned byte.
+func (r *reader) u8() int {
+	if r.off < 0 || r.off+1 > len(r.b) {
+		r.bad = true
+		return 0
+	}
+	v := int(r.b[r.off])
+	r.off++
+	return v
+}
+
+// u16 reads an unsigned 16 bit number.
+func (r *reader) u16() int {
+	if r.off < 0 || r.off+2 > len(r.b) {
+		r.bad = true
+		return 0
+	}
+	v := int(r.b[r.off])<<8 | int(r.b[r.off+1])
+	r.off += 2
+	return v
+}
+
+// i16 reads a signed 16 bit number.
+func (r *reader) i16() int {
+	return int(int16(r.u16()))
+}
+
+// u32 reads an unsigned 32 bit number.
+func (r *reader) u32() int {
+	return r.u16()<<16 | r.u16()
+}
+
+// at returns a new reader starting at off.
+func (r *reader) at(off int) *reader {
+	return &reader{b: r.b, off: off}
+}
+
+// Font is a parsed TrueType font, or an OpenType font with TrueType outlines.
+// Fonts with CFF outlines and font collections are not supported. Hinting
+// and kerning are ignored.
+type Font struct {
+
+	// Tables used for drawing
+	cmap, glyf, hmtx, loca []byte
+
+	// Number of font units per em
+	unitsPerEm float64
+
+	// True if loca has 32 bit offsets
+	locaLong bool
+
+	numGlyphs int
+
+	// Number of glyphs with their own advance in hmtx
+	numHMetrics int
+
+	// Vertical metrics in font units
+	ascent, descent, lineGap int
+
+	// Format and offset of the chosen cmap subtable
+	cmapFormat int
+	cmapOff    int
+}
+
+// ParseFont parses a TrueType font from its file content.
+func ParseFont(data []byte) (*Font, error) {
+	r := &reader{b: data}
+	switch r.u32() {
+	case 0x00010000, 0x74727565:
+	case 0x4f54544f:
+		return nil, errors.New("Unsupported font with CFF outlines")
+	case 0x74746366:
+		return nil, errors.New("Unsupported font collection")
+	default:
+		return nil, errors.New("Not a TrueType font")
+	}
+	n := r.u16()
+	if len(data) < 12+16*n {
+		return nil, errors.New("Invalid font table directory")
+	}
+	tables := make(map[string][]byte)
+	for i := 0; i < n; i++ {
+		t := r.at(12 + 16*i)
+		tag := string(data[t.off : t.off+4])
+		t.off += 8
+		off := t.u32()
+		l := t.u32()
+		if t.bad || off+l > len(data) {
+			return nil, errors.New("Invalid font table: " + tag)
+		}
+		tables[tag] = data[off : off+l]
+	}
+	for _, tag := range []string{"cmap", "glyf", "head", "hhea", "hmtx", "loca", "maxp"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, errors.New("Missing font table: " + tag)
+		}
+	}
+	f := &Font{
+		cmap: tables["cmap"],
+		glyf: tables["glyf"],
+		hmtx: tables["hmtx"],
+		loca: tables["loca"],
+	}
+	head := &reader{b: tables["head"], off: 18}
+	f.unitsPerEm = float64(head.u16())
+	head.off = 50
+	f.locaLong = head.i16() == 1
+	maxp := &reader{b: tables["maxp"], off: 4}
+	f.numGlyphs = maxp.u16()
+	hhea := &reader{b: tables["hhea"], off: 4}
+	f.ascent = hhea.i16()
+	f.descent = hhea.i16()
+	f.lineGap = hhea.i16()
+	hhea.off = 34
+	f.numHMetrics = hhea.u16()
+	if head.bad || maxp.bad || hhea.bad || f.unitsPerEm == 0 || f.numHMetrics == 0 {
+		return nil, errors.New("Invalid font header")
+	}
+	if err := f.parseCmap(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseCmap chooses the subtable mapping Unicode characters to glyphs,
+// preferring the full Unicode range (format 12) over the basic plane
+// (format 4).
+func (f *Font) parseCmap() error {
+	r := &reader{b: f.cmap, off: 2}
+	n := r.u16()
+	for i := 0; i < n; i++ {
+		platform := r.u16()
+		encoding := r.u16()
+		off := r.u32()
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		format := r.at(off).u16()
+		if format == 12 || (format == 4 && f.cmapFormat != 12) {
+			f.cmapFormat = format
+			f.cmapOff = off
+		}
+	}
+	if r.bad || f.cmapFormat == 0 {
+		return errors.New("Unsupported font character map")
+	}
+	return nil
+}
+
+// Index returns the index of the glyph for r, 0 if the font has none.
+func (f *Font) Index(r rune) int {
+	c := int(r)
+	t := &reader{b: f.cmap, off: f.cmapOff}
+	if f.cmapFormat == 12 {
+		t.off += 12
+		n := t.u32()
+		for i := 0; i < n && !t.bad; i++ {
+			start := t.u32()
+			end := t.u32()
+			g := t.u32()
+			if c >= start && c <= end {
+				return g + c - start
+			}
+		}
+		return 0
+	}
+	if c > 0xffff {
+		return 0
+	}
+	t.off += 6
+	segs := t.u16() / 2
+	ends := f.cmapOff + 14
+	for i := 0; i < segs; i++ {
+		end := t.at(ends + 2*i).u16()
+		if end < c {
+			continue
+		}
+		start := t.at(ends + 2*segs + 2 + 2*i).u16()
+		if start > c {
+			return 0
+		}
+		delta := t.at(ends + 4*segs + 2 + 2*i).u16()
+		rangeOff := ends + 6*segs + 2 + 2*i
+		ro := t.at(rangeOff).u16()
+		if ro == 0 {
+			return (c + delta) & 0xffff
+		}
+		g := t.at(rangeOff + ro + 2*(c-start)).u16()
+		if g == 0 {
+			return 0
+		}
+		return (g + delta) & 0xffff
+	}
+	return 0
+}
+
+// advance returns the advance width of glyph g in font units.
+func (f *Font) advance(g int) int {
+	if g >= f.numHMetrics {
+		g = f.numHMetrics - 1
+	}
+	return (&reader{b: f.hmtx, off: 4 * g}).u16()
+}
+
+// glyphData returns the outline data of glyph g, nil if it has none.
+func (f *Font) glyphData(g int) []byte {
+	if g < 0 || g >= f.numGlyphs {
+		return nil
+	}
+	r := &reader{b: f.loca}
+	var start, end int
+	if f.locaLong {
+		r.off = 4 * g
+		start = r.u32()
+		end = r.u32()
+	} else {
+		r.off = 2 * g
+		start = 2 * r.u16()
+		end = 2 * r.u16()
+	}
+	if r.bad || start >= end || end > len(f.glyf) {
+		return nil
+	}
+	return f.glyf[start:end]
+}
+
+// point is a point of a glyph outline in font units.
+type point struct {
+	x, y float64
+	on   bool
+}
+
+// transf is an affine transformation of glyph points.
+type transf struct {
+	xx, xy, yx, yy, dx, dy float64
+}
+
+// apply returns p transformed.
+func (t *transf) apply(p point) point {
+	return point{
+		t.xx*p.x + t.yx*p.y + t.dx,
+		t.xy*p.x + t.yy*p.y + t.dy,
+		p.on,
+	}
+}
+
+// contours appends the transformed contours of glyph g. Compound glyphs are
+// resolved into the contours of their components.
+func (f *Font) contours(cs [][]point, g int, t transf, depth int) [][]point {
+	data := f.glyphData(g)
+	if data == nil || depth > maxDepth {
+		return cs
+	}
+	r := &reader{b: data}
+	nc := r.i16()
+	r.off = 10
+	if nc < 0 {
+		for {
+			flags := r.u16()
+			comp := r.u16()
+			var dx, dy int
+			if flags&flagArgWords != 0 {
+				dx = r.i16()
+				dy = r.i16()
+			} else {
+				dx = int(int8(r.u8()))
+				dy = int(int8(r.u8()))
+			}
+			// Aligning points instead of offsets is not supported
+			if flags&flagArgXY == 0 {
+				dx, dy = 0, 0
+			}
+			c := transf{1, 0, 0, 1, float64(dx), float64(dy)}
+			switch {
+			case flags&flagScale != 0:
+				c.xx = f2dot14(r.i16())
+				c.yy = c.xx
+			case flags&flagXYScale != 0:
+				c.xx = f2dot14(r.i16())
+				c.yy = f2dot14(r.i16())
+			case flags&flagTwoByTwo != 0:
+				c.xx = f2dot14(r.i16())
+				c.xy = f2dot14(r.i16())
+				c.yx = f2dot14(r.i16())
+				c.yy = f2dot14(r.i16())
+			}
+			if r.bad {
+				return cs
+			}
+			// Component transformation first, then the parent's
+			c = transf{
+				t.xx*c.xx + t.yx*c.xy,
+				t.xy*c.xx + t.yy*c.xy,
+				t.xx*c.yx + t.yx*c.yy,
+				t.xy*c.yx + t.yy*c.yy,
+				t.xx*c.dx + t.yx*c.dy + t.dx,
+				t.xy*c.dx + t.yy*c.dy + t.dy,
+			}
+			cs = f.contours(cs, comp, c, depth+1)
+			if flags&flagMore == 0 {
+				return cs
+			}
+		}
+	}
+	ends := make([]int, nc)
+	for i := range ends {
+		ends[i] = r.u16()
+	}
+	if nc == 0 {
+		return cs
+	}
+	n := ends[nc-1] + 1
+	r.off += r.u16()
+	flags := make([]int, 0, n)
+	for len(flags) < n && !r.bad {
+		fl := r.u8()
+		flags = append(flags, fl)
+		if fl&flagRepeat != 0 {
+			for k := r.u8(); k > 0 && len(flags) < n; k-- {
+				flags = append(flags, fl)
+			}
+		}
+	}
+	pts := make([]point, n)
+	x := 0
+	for i, fl := range flags {
+		switch {
+		case fl&flagXShort != 0 && fl&flagXSame != 0:
+			x += r.u8()
+		case fl&flagXShort != 0:
+			x -= r.u8()
+		case fl&flagXSame == 0:
+			x += r.i16()
+		}
+		pts[i].x = float64(x)
+		pts[i].on = fl&flagOnCurve != 0
+	}
+	y := 0
+	for i, fl := range flags {
+		switch {
+		case fl&flagYShort != 0 && fl&flagYSame != 0:
+			y += r.u8()
+		case fl&flagYShort != 0:
+			y -= r.u8()
+		case fl&flagYSame == 0:
+			y += r.i16()
+		}
+		pts[i].y = float64(y)
+	}
+	if r.bad {
+		return cs
+	}
+	start := 0
+	for _, end := range ends {
+		if end < start || end >= n {
+			break
+		}
+		c := make([]point, end+1-start)
+		for i := range c {
+			c[i] = t.apply(pts[start+i])
+		}
+		cs = append(cs, c)
+		start = end + 1
+	}
+	return cs
+}
+
+// f2dot14 converts a signed 2.14 fixed point number.
+func f2dot14(v int) float64 {
+	return float64(v) / (1 << 14)
+}
+
+// midpoint returns the on-curve point halfway between a and b.
+func midpoint(a, b point) point {
+	return point{(a.x + b.x) / 2, (a.y + b.y) / 2, true}
+}
+
+// addContour adds a closed contour of on-curve points and quadratic control
+// points to the path. Between two consecutive control points there is an
+// implicit on-curve point in the middle.
+func addContour(p *draw.Path, c []point) {
+	var start point
+	seq := c
+	switch {
+	case c[0].on:
+		start = c[0]
+		seq = c[1:]
+	case c[len(c)-1].on:
+		start = c[len(c)-1]
+		seq = c[:len(c)-1]
+	default:
+		start = midpoint(c[len(c)-1], c[0])
+	}
+	p.MoveTo(start.x, start.y)
+	var ctrl *point
+	for i := range seq {
+		q := seq[i]
+		switch {
+		case q.on && ctrl == nil:
+			p.LineTo(q.x, q.y)
+		case q.on:
+			p.QuadTo(ctrl.x, ctrl.y, q.x, q.y)
+			ctrl = nil
+		case ctrl != nil:
+			m := midpoint(*ctrl, q)
+			p.QuadTo(ctrl.x, ctrl.y, m.x, m.y)
+			ctrl = &seq[i]
+		default:
+			ctrl = &seq[i]
+		}
+	}
+	if ctrl != nil {
+		p.QuadTo(ctrl.x, ctrl.y, start.x, start.y)
+	}
+	p.Close()
+}
+
+// FontFace is a TrueType font at a certain size.
+type FontFace struct {
+
+	// Font to draw with
+	Font *Font
+
+	// Size is the em size in pixels.
+	Size float64
+}
+
+// NewFontFace returns a new face for font f with an em size of size pixels.
+func NewFontFace(f *Font, size float64) *FontFace {
+	return &FontFace{f, size}
+}
+
+// scale returns pixels per font unit.
+func (f *FontFace) scale() float64 {
+	return f.Size / f.Font.unitsPerEm
+}
+
+// Metrics returns the vertical metrics of the face from the font's
+// horizontal header.
+func (f *FontFace) Metrics() Metrics {
+	s := f.scale()
+	fo := f.Font
+	return Metrics{
+		Ascent:  s * float64(fo.ascent),
+		Descent: -s * float64(fo.descent),
+		Height:  s * float64(fo.ascent-fo.descent+fo.lineGap),
+	}
+}
+
+// Advance returns the advance width of the glyph for r.
+func (f *FontFace) Advance(r rune) float64 {
+	return f.scale() * float64(f.Font.advance(f.Font.Index(r)))
+}
+
+// DrawGlyph draws the outline of the glyph for r filled with anti-aliasing.
+func (f *FontFace) DrawGlyph(dst stddraw.Image, x, y float64, r rune, c color.Color) {
+	s := f.scale()
+	cs := f.Font.contours(nil, f.Font.Index(r), transf{s, 0, 0, -s, x, y}, 0)
+	if len(cs) == 0 {
+		return
+	}
+	p := draw.NewPath()
+	for _, c := range cs {
+		addContour(p, c)
+	}
+	draw.Fill(dst, p, c, draw.NonZero, true)
+}
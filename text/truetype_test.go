@@ -0,0 +1,145 @@
+package text
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/amsibamsi/three/draw"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// be writes big-endian numbers.
+func be(b *bytes.Buffer, vs ...int) {
+	for _, v := range vs {
+		binary.Write(b, binary.BigEndian, uint16(v))
+	}
+}
+
+// testFont returns a TrueType font with 1000 units per em and 3 glyphs:
+// 0 is empty, 1 ('A') is a square from (0,0) to (500,500) and 2 ('B') is a
+// compound glyph of the square moved 500 units to the right.
+func testFont() []byte {
+	tables := []struct {
+		tag  string
+		data bytes.Buffer
+	}{
+		{tag: "cmap"}, {tag: "glyf"}, {tag: "head"}, {tag: "hhea"},
+		{tag: "hmtx"}, {tag: "loca"}, {tag: "maxp"},
+	}
+	// cmap with one format 4 subtable, 'A' and 'B' mapped by delta
+	b := &tables[0].data
+	be(b, 0, 1, 3, 1, 0, 12)
+	be(b, 4, 32, 0, 4, 4, 1, 0)
+	be(b, 'B', 0xffff, 0, 'A', 0xffff, -64, 1, 0, 0)
+	// glyf
+	b = &tables[1].data
+	be(b, 1, 0, 0, 500, 500, 3, 0)
+	b.Write([]byte{flagOnCurve, flagOnCurve, flagOnCurve, flagOnCurve})
+	be(b, 0, 500, 0, -500, 0, 0, 500, 0)
+	be(b, -1, 500, 0, 1000, 500, flagArgWords|flagArgXY, 1, 500, 0)
+	// head with units per em and short loca
+	b = &tables[2].data
+	b.Write(make([]byte, 18))
+	be(b, 1000)
+	b.Write(make([]byte, 30))
+	be(b, 0, 0)
+	// hhea with ascent, descent, line gap and number of metrics
+	b = &tables[3].data
+	be(b, 1, 0, 800, -200, 100)
+	b.Write(make([]byte, 24))
+	be(b, 3)
+	// hmtx
+	b = &tables[4].data
+	be(b, 500, 0, 600, 0, 1100, 0)
+	// loca
+	b = &tables[5].data
+	be(b, 0, 0, 17, 26)
+	// maxp
+	b = &tables[6].data
+	be(b, 0, 0x5000, 3)
+	var f bytes.Buffer
+	be(&f, 1, 0, len(tables), 0, 0, 0)
+	off := 12 + 16*len(tables)
+	for i := range tables {
+		f.WriteString(tables[i].tag)
+		be(&f, 0, 0)
+		binary.Write(&f, binary.BigEndian, uint32(off))
+		binary.Write(&f, binary.BigEndian, uint32(tables[i].data.Len()))
+		off += tables[i].data.Len()
+	}
+	for i := range tables {
+		f.Write(tables[i].data.Bytes())
+	}
+	return f.Bytes()
+}
+
+func TestParseFont(t *testing.T) {
+	if _, err := ParseFont([]byte("OTTO")); err == nil {
+		t.Errorf("expected error for CFF font")
+	}
+	if _, err := ParseFont(testFont()[:40]); err == nil {
+		t.Errorf("expected error for truncated font")
+	}
+	f, err := ParseFont(testFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, g := range map[rune]int{'A': 1, 'B': 2, 'C': 0, 0x1f600: 0} {
+		if i := f.Index(r); i != g {
+			t.Errorf("expected '%v' but got '%v'", g, i)
+		}
+	}
+	face := NewFontFace(f, 10)
+	m := Metrics{Ascent: 8, Descent: 2, Height: 11}
+	if fm := face.Metrics(); fm != m {
+		t.Errorf("expected '%v' but got '%v'", m, fm)
+	}
+	if a := face.Advance('B'); a != 11 {
+		t.Errorf("expected '%v' but got '%v'", 11, a)
+	}
+	if a := face.Advance('?'); a != 5 {
+		t.Errorf("expected '%v' but got '%v'", 5, a)
+	}
+}
+
+var glyphtests = []struct {
+	r    rune
+	rect image.Rectangle
+}{
+	{'A', image.Rect(2, 5, 7, 10)},
+	{'B', image.Rect(7, 5, 12, 10)},
+	{'C', image.Rectangle{}},
+}
+
+func TestFontGlyph(t *testing.T) {
+	f, _ := ParseFont(testFont())
+	face := NewFontFace(f, 10)
+	for _, test := range glyphtests {
+		img := newRgba(20, 20)
+		face.DrawGlyph(img, 2, 10, test.r, color.White)
+		if b := bounds(img); b != test.rect {
+			t.Errorf("expected '%v' but got '%v'", test.rect, b)
+		}
+		if n := count(img); n != test.rect.Dx()*test.rect.Dy() {
+			t.Errorf("expected '%v' pixels but got '%v'", test.rect.Dx()*test.rect.Dy(), n)
+		}
+	}
+}
+
+func TestAddContour(t *testing.T) {
+	// Only control points, on-curve points are implied between them
+	c := []point{{0, 0, false}, {10, 0, false}, {10, 10, false}, {0, 10, false}}
+	img := newRgba(20, 20)
+	p := draw.NewPath()
+	addContour(p, c)
+	draw.Fill(img, p, color.White, draw.NonZero, true)
+	// Rounded shape inside the square from (0,0) to (10,10) touching the
+	// middle of each side
+	if b := bounds(img); b != image.Rect(0, 0, 10, 10) {
+		t.Errorf("expected '%v' but got '%v'", image.Rect(0, 0, 10, 10), b)
+	}
+	if img.RGBAAt(0, 0).A != 0 || img.RGBAAt(5, 5).A != 255 {
+		t.Errorf("expected empty corner and filled center")
+	}
+}
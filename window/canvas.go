@@ -0,0 +1,43 @@
+package window
+
+import (
+	stdimage "image"
+	"image/color"
+)
+
+// Canvas is the content of a window as an image to draw on. It implements
+// draw.Image from the standard library, so it can be used with package draw
+// the same way as images. The canvas always has the current size of the
+// window. Colors are stored without alpha, setting a translucent color stores
+// it as if drawn on black. Package draw composites translucent colors over
+// the existing content instead.
+type Canvas struct {
+	w *Window
+}
+
+// Canvas returns the window content as canvas.
+func (w *Window) Canvas() *Canvas {
+	return &Canvas{w}
+}
+
+// ColorModel returns the RGBA color model.
+func (c *Canvas) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds returns the rectangle from (0,0) to the window's width and height.
+func (c *Canvas) Bounds() stdimage.Rectangle {
+	return stdimage.Rect(0, 0, c.w.width, c.w.height)
+}
+
+// At returns the opaque color of pixel (x,y).
+func (c *Canvas) At(x, y int) color.Color {
+	r, g, b := c.w.Getxy(x, y)
+	return color.RGBA{r, g, b, 255}
+}
+
+// Set sets the color of pixel (x,y).
+func (c *Canvas) Set(x, y int, col color.Color) {
+	rgba := color.RGBAModel.Convert(col).(color.RGBA)
+	c.w.Setxy(x, y, rgba.R, rgba.G, rgba.B)
+}
@@ -0,0 +1,82 @@
+package window
+
+import (
+	"fmt"
+	"os"
+)
+
+// Maximum number of file names tried by Capture before giving up
+const maxCaptureTries = 10000
+
+// Capturer writes screenshots of a window to numbered PNG files. Existing files
+// are never overwritten, numbers are skipped until a free file name is found.
+type Capturer struct {
+
+	// Pattern is the file name with a formatting verb for the number, e.g.
+	// "three%04d.png".
+	Pattern string
+
+	// Next is the number tried first for the next file.
+	Next int
+
+	// Key triggers a capture when polled.
+	Key Key
+
+	// Whether the key was down at the last poll
+	down bool
+}
+
+// NewCapturer returns a new capturer that names files after the given pattern,
+// starts numbering at 1 and captures on F12.
+func NewCapturer(pattern string) *Capturer {
+	return &Capturer{Pattern: pattern, Next: 1, Key: KeyF12}
+}
+
+// Capture writes the current window content to the next free numbered file and
+// returns its name. Returns an error if a file cannot be checked or no free name
+// is found within a limited number of tries.
+func (c *Capturer) Capture(w *Window) (string, error) {
+	name := ""
+	for i := 0; i < maxCaptureTries; i++ {
+		n := fmt.Sprintf(c.Pattern, c.Next)
+		c.Next++
+		_, err := os.Stat(n)
+		if os.IsNotExist(err) {
+			name = n
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("No free file name for pattern '%v'", c.Pattern)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	err = w.WritePng(f)
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Poll captures the window content if the capture key has been pressed since
+// the last poll. Holding the key down only captures once. Returns the name of
+// the file written or "" if nothing was captured. Should be called once per
+// frame, e.g. before Update.
+func (c *Capturer) Poll(w *Window) (string, error) {
+	down := w.KeyDown(c.Key)
+	pressed := down && !c.down
+	c.down = down
+	if !pressed {
+		return "", nil
+	}
+	return c.Capture(w)
+}
@@ -1,26 +1,52 @@
 // Package window provides windowing, drawing and processing input events.
 //
-// It needs GLFW3 and GLEW libraries installed on the system and calls them
-// with the help of Cgo. For compilation header files are also required.
+// It needs GLFW 3.2 or newer and GLEW libraries installed on the system and
+// calls them with the help of Cgo. For compilation header files are also
+// required.
 //
 // Quickstart
 //
-//   1. Create new window with NewWindow()
-//   2. Periodically:
-//      - Draw to the window with Set()
+//   1. Create new window with NewWindow(), or NewWindowOptions() for more
+//      settings like fullscreen on one of the Monitors()
+//   2. Periodically (package loop can run this for you):
+//      - Draw to the window with Set(), or with packages draw and text on
+//        Canvas()
 //      - Call Update()
 //      - Stop if ShouldClose() returns true
 //   3. Call Destroy() on the window
 //   4. Call Terminate() at end of program (defer after window was created)
 //
+// Several windows can be open at the same time, e.g. a main view and a debug
+// view. Each one is updated and destroyed on its own. GLFW is initialized with
+// the first window and terminated when the last one is destroyed.
+//
+// The cursor is shown and free to leave a window unless captured with
+// Options.CaptureCursor or SetCursorCaptured. Earlier versions captured it in
+// every window, programs that relied on that need to ask for it now.
+//
+// Threads
+//
+// GLFW must only be called from the main thread. The package keeps the main
+// goroutine there, so windows can always be used from it. To use windows from
+// other goroutines run the program in Main. Functions and methods of the
+// package then send their GLFW calls to the main thread and wait for them. Do
+// does the same for any other function, e.g. one with OpenGL calls. Drawing to
+// a window's content doesn't call GLFW and happens on the calling goroutine,
+// concurrent drawing and updating needs synchronization as with any data.
+//
 // Implementation
 //
-// A window holds a GLFW window and some texture data. The texture matches
-// exactly the number pixels of the window content. When drawing with OpenGL a
-// single rectangle with this texture is created and drawn with an orthographic
-// projection to fill the whole window. The texture is uploaded and drawn every
-// frame. The only reason for choosing OpenGL was that GLFW and GLEW present
-// platform independent and realtively easy to use C APIs that can be used from
-// Go. The performance benefit from offloading graphics to the GPU is not
-// really used.
+// A window holds a GLFW window and some texture data. By default the texture
+// matches exactly the number of pixels of the window content on screen, which
+// on scaled (HiDPI) displays is more than its size in screen coordinates. With
+// a fixed or scaled resolution the texture is stretched to fill the window.
+// When drawing with OpenGL a single rectangle with this texture is created and
+// drawn with an orthographic projection to fill the whole window. The texture
+// is drawn every frame. Only the area changed since the last frame is
+// uploaded, in the layout the hardware uses (BGRA). Partial uploads go through
+// a pixel buffer object if supported, so they don't stall the program, full
+// ones are uploaded directly to save a copy. The only reason for choosing
+// OpenGL was that GLFW and GLEW present platform independent and realtively
+// easy to use C APIs that can be used from Go. The performance benefit from
+// offloading graphics to the GPU is not really used.
 package window
@@ -0,0 +1,75 @@
+package window
+
+/*
+#include <GLFW/glfw3.h>
+#include "window.h"
+*/
+import "C"
+
+const (
+	MouseLeft   = C.GLFW_MOUSE_BUTTON_LEFT
+	MouseRight  = C.GLFW_MOUSE_BUTTON_RIGHT
+	MouseMiddle = C.GLFW_MOUSE_BUTTON_MIDDLE
+)
+
+// MouseButton is a wrapper for GLFW mouse button codes.
+type MouseButton C.int
+
+// windows maps GLFW windows to their window to dispatch callbacks.
+var windows = make(map[*C.GLFWwindow]*Window)
+
+//export goScroll
+func goScroll(win *C.GLFWwindow, x, y C.double) {
+	if w, ok := windows[win]; ok {
+		w.scrollNext[0] += float64(x)
+		w.scrollNext[1] += float64(y)
+	}
+}
+
+// MouseDown returns true if the mouse button is currently (since the last
+// polling of events) pressed down. Otherwise it returns false.
+func (w *Window) MouseDown(b MouseButton) bool {
+	var state C.int
+	Do(func() {
+		state = C.glfwGetMouseButton(w.glfwWin, C.int(b))
+	})
+	return state == C.GLFW_PRESS
+}
+
+// CursorPos returns the position of the cursor in pixels of the window content
+// as drawn to, relative to its top left corner. With the cursor captured the
+// position is not limited to the window, only its changes are meaningful then.
+func (w *Window) CursorPos() (float64, float64) {
+	var x, y C.double
+	sx, sy := 1.0, 1.0
+	Do(func() {
+		C.glfwGetCursorPos(w.glfwWin, &x, &y)
+		// The system gives screen coordinates
+		if w.winWidth > 0 && w.winHeight > 0 {
+			sx = float64(w.width) / float64(w.winWidth)
+			sy = float64(w.height) / float64(w.winHeight)
+		}
+	})
+	return float64(x) * sx, float64(y) * sy
+}
+
+// Scroll returns how far the mouse wheel or touchpad was scrolled
+// horizontally and vertically between the last two updates. A normal mouse
+// wheel scrolls 1 per notch vertically, positive away from the user.
+func (w *Window) Scroll() (float64, float64) {
+	return w.scroll[0], w.scroll[1]
+}
+
+// SetCursorCaptured hides the cursor and captures it in the window if on. A
+// captured cursor can move without limits, which is useful to look around with
+// the mouse. If off, which is the default unless set in the window's options,
+// the cursor is shown and can leave the window.
+func (w *Window) SetCursorCaptured(on bool) {
+	mode := C.GLFW_CURSOR_NORMAL
+	if on {
+		mode = C.GLFW_CURSOR_DISABLED
+	}
+	Do(func() {
+		C.glfwSetInputMode(w.glfwWin, C.GLFW_CURSOR, C.int(mode))
+	})
+}
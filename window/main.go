@@ -0,0 +1,80 @@
+package window
+
+/*
+#include <GL/glew.h>
+#include <GLFW/glfw3.h>
+#include "window.h"
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync"
+)
+
+func init() {
+	// GLFW must be used from the main thread on some platforms. The main
+	// goroutine starts on it, keep it there.
+	runtime.LockOSThread()
+}
+
+var (
+	// Guards calls
+	callsMu sync.RWMutex
+
+	// Functions to run on the main thread while Main runs, nil otherwise
+	calls chan func()
+)
+
+// Main runs f in a new goroutine and, until f returns, runs the functions
+// passed to Do on the calling goroutine. It must be called from the main
+// goroutine, usually right at the start of main with the rest of the program
+// in f. Then windows can be used from any goroutine: all functions and methods
+// of this package that call GLFW or OpenGL are run on the main thread, where
+// GLFW needs them. They must not be called from other goroutines after Main
+// returned.
+//
+// Without Main a program can still use windows, but only from the main
+// goroutine.
+func Main(f func()) {
+	C.setMainThread()
+	callsMu.Lock()
+	calls = make(chan func())
+	callsMu.Unlock()
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		f()
+	}()
+	for {
+		select {
+		case c := <-calls:
+			c()
+		case <-done:
+			callsMu.Lock()
+			calls = nil
+			callsMu.Unlock()
+			return
+		}
+	}
+}
+
+// Do runs f on the main thread and returns when f returned. Outside of Main,
+// or when already on the main thread, f is run directly. Use it for other
+// calls that need the main thread, e.g. custom OpenGL calls. Functions and
+// methods of this package do this on their own.
+func Do(f func()) {
+	callsMu.RLock()
+	c := calls
+	callsMu.RUnlock()
+	if c == nil || C.isMainThread() == 1 {
+		f()
+		return
+	}
+	done := make(chan bool)
+	c <- func() {
+		f()
+		close(done)
+	}
+	<-done
+}
@@ -0,0 +1,143 @@
+package window
+
+/*
+#include <GLFW/glfw3.h>
+#include "window.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// errVideoMode is returned when GLFW knows no video mode for a monitor.
+var errVideoMode = errors.New("Failed to get video mode of monitor")
+
+// Monitor is a screen connected to the system. Monitors are only valid while
+// windowing is initialized: from asking for them or creating a window until
+// the last window is destroyed or Terminate is called.
+type Monitor struct {
+	m *C.GLFWmonitor
+}
+
+// VideoMode is a resolution and color depth a monitor can run at.
+type VideoMode struct {
+
+	// Width and Height in pixels
+	Width, Height int
+
+	// Bits per pixel for red, green and blue
+	RedBits, GreenBits, BlueBits int
+
+	// RefreshRate in Hz
+	RefreshRate int
+}
+
+// newVideoMode returns the video mode for a GLFW video mode.
+func newVideoMode(m *C.GLFWvidmode) VideoMode {
+	return VideoMode{
+		Width:       int(m.width),
+		Height:      int(m.height),
+		RedBits:     int(m.redBits),
+		GreenBits:   int(m.greenBits),
+		BlueBits:    int(m.blueBits),
+		RefreshRate: int(m.refreshRate),
+	}
+}
+
+// Monitors returns all connected monitors, the primary one first. It
+// initializes windowing if needed.
+func Monitors() (ms []*Monitor, err error) {
+	Do(func() {
+		if err = ensGlfw(); err != nil {
+			return
+		}
+		var n C.int
+		p := C.glfwGetMonitors(&n)
+		if p == nil {
+			return
+		}
+		cms := (*[1 << 16]*C.GLFWmonitor)(unsafe.Pointer(p))[:n:n]
+		for _, m := range cms {
+			ms = append(ms, &Monitor{m})
+		}
+	})
+	return
+}
+
+// PrimaryMonitor returns the user's preferred monitor, usually the one with
+// the task bar or menu bar. It initializes windowing if needed.
+func PrimaryMonitor() (m *Monitor, err error) {
+	Do(func() {
+		if err = ensGlfw(); err != nil {
+			return
+		}
+		p := C.glfwGetPrimaryMonitor()
+		if p == nil {
+			err = errors.New("No monitor found")
+			return
+		}
+		m = &Monitor{p}
+	})
+	return
+}
+
+// Name returns a human readable name of the monitor, not necessarily unique.
+func (m *Monitor) Name() (name string) {
+	Do(func() {
+		name = C.GoString(C.glfwGetMonitorName(m.m))
+	})
+	return
+}
+
+// Pos returns the position of the monitor's top left corner on the virtual
+// screen spanning all monitors.
+func (m *Monitor) Pos() (int, int) {
+	var x, y C.int
+	Do(func() {
+		C.glfwGetMonitorPos(m.m, &x, &y)
+	})
+	return int(x), int(y)
+}
+
+// PhysicalSize returns the width and height of the monitor's display area in
+// millimeters, or 0 if unknown.
+func (m *Monitor) PhysicalSize() (int, int) {
+	var w, h C.int
+	Do(func() {
+		C.glfwGetMonitorPhysicalSize(m.m, &w, &h)
+	})
+	return int(w), int(h)
+}
+
+// VideoMode returns the current video mode of the monitor. Returns an error if
+// it is unknown, e.g. because the monitor was disconnected.
+func (m *Monitor) VideoMode() (mode VideoMode, err error) {
+	Do(func() {
+		p := C.glfwGetVideoMode(m.m)
+		if p == nil {
+			err = errVideoMode
+			return
+		}
+		mode = newVideoMode(p)
+	})
+	return
+}
+
+// VideoModes returns all video modes supported by the monitor, sorted by
+// color depth and then resolution in ascending order.
+func (m *Monitor) VideoModes() (modes []VideoMode) {
+	Do(func() {
+		var n C.int
+		p := C.glfwGetVideoModes(m.m, &n)
+		if p == nil {
+			return
+		}
+		cms := (*[1 << 16]C.GLFWvidmode)(unsafe.Pointer(p))[:n:n]
+		for i := range cms {
+			modes = append(modes, newVideoMode(&cms[i]))
+		}
+	})
+	return
+}
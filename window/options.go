@@ -0,0 +1,62 @@
+package window
+
+import (
+	"github.com/amsibamsi/three/math/geom"
+)
+
+// Options configure a new window.
+type Options struct {
+
+	// Title shown in the window's title bar.
+	Title string
+
+	// Width and Height of the window content in screen coordinates. On scaled
+	// (HiDPI) displays the content has more pixels. In fullscreen they select
+	// the video mode closest to them, 0 keeps the monitor's current video
+	// mode.
+	Width, Height int
+
+	// Visible shows the window when created. Hidden windows are useful for
+	// tests or to render offscreen.
+	Visible bool
+
+	// Monitor shows the window fullscreen on the monitor, nil creates a normal
+	// window.
+	Monitor *Monitor
+
+	// Borderless makes a fullscreen window cover the monitor at its current
+	// video mode instead of changing it (windowed fullscreen). Width and
+	// Height are ignored then.
+	Borderless bool
+
+	// Resizable lets the user resize a normal window.
+	Resizable bool
+
+	// Decorated gives a normal window a border and a title bar.
+	Decorated bool
+
+	// Pos is the initial position of a normal window's content on the virtual
+	// screen spanning all monitors. If nil the system chooses one.
+	Pos *geom.Vec2
+
+	// Interval is the number of screen refreshes to wait for when updating. 0
+	// disables V-Sync, 1 enables it. A loop with NoVsync switches it off.
+	Interval int
+
+	// CaptureCursor hides the cursor and captures it in the window like
+	// SetCursorCaptured.
+	CaptureCursor bool
+}
+
+// DefOptions returns options for a visible, resizable and decorated normal
+// window of 1024x768 pixels with V-Sync on and a free cursor.
+func DefOptions() *Options {
+	return &Options{
+		Width:     1024,
+		Height:    768,
+		Visible:   true,
+		Resizable: true,
+		Decorated: true,
+		Interval:  1,
+	}
+}
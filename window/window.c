@@ -1,4 +1,6 @@
+#include <pthread.h>
 #include <stdio.h>
+#include <string.h>
 #include <GL/glew.h>
 #include <GLFW/glfw3.h>
 
@@ -23,20 +25,18 @@ int initGlfw() {
 
 // Create a GLFW window.
 // Initializes the window with OpenGL API 2.1 and sets the swap interval to 1.
-// Returns the window in case of success and otherwise NULL. Errors will be
-// handled by the error callback.
+// Other window hints must be set before. The window is fullscreen on the
+// monitor if not NULL. Returns the window in case of success and otherwise
+// NULL. Errors will be handled by the error callback.
 GLFWwindow* createWin(int width,
                       int height,
                       char* title,
-                      int visible) {
+                      GLFWmonitor* monitor) {
   GLFWwindow* win;
   glfwWindowHint(GLFW_CLIENT_API, GLFW_OPENGL_API);
   glfwWindowHint(GLFW_CONTEXT_VERSION_MAJOR, 2);
   glfwWindowHint(GLFW_CONTEXT_VERSION_MINOR, 1);
-  if (!visible) {
-    glfwWindowHint(GLFW_VISIBLE, GL_FALSE);
-  }
-  win = glfwCreateWindow(width, height, title, NULL, NULL);
+  win = glfwCreateWindow(width, height, title, monitor, NULL);
   if (win != NULL) {
     glfwMakeContextCurrent(win);
     glfwSwapInterval(1);
@@ -44,6 +44,14 @@ GLFWwindow* createWin(int width,
   return win;
 }
 
+// Sets the swap interval of the window's context.
+// An interval of 0 disables V-Sync, 1 waits for one screen refresh.
+void setSwapInterval(GLFWwindow* win,
+                     int interval) {
+  glfwMakeContextCurrent(win);
+  glfwSwapInterval(interval);
+}
+
 // Initializes GLEW on the given window.
 // Makes the window and it's context ready for OpenGL calls. Returns 0 for
 // success and otherwise 1. Errors will be printed to stderr.
@@ -59,17 +67,16 @@ int initGlew(GLFWwindow* win) {
   return 1;
 }
 
-// Initializes a window after GLEW has been initialized.
-// Sets the viewport to the current width and height.
-void initWin(GLFWwindow* win,
-             int width,
-             int height) {
-  glfwMakeContextCurrent(win);
-  glViewport(0, 0, (GLsizei)width, (GLsizei)height);
+// Scroll callback implemented in Go.
+extern void goScroll(GLFWwindow* win, double x, double y);
+
+// Sets the callbacks for input events that can't be polled.
+void setCallbacks(GLFWwindow* win) {
+  glfwSetScrollCallback(win, goScroll);
 }
 
-// Adapts the window after it has been resized.
-// Resets the viewport.
+// Adapts the window after its framebuffer has been resized.
+// Resets the viewport to the framebuffer size in pixels.
 void winResized(GLFWwindow* win,
                 int width,
                 int height) {
@@ -79,10 +86,10 @@ void winResized(GLFWwindow* win,
 
 // Creates the texture to be drawn as content of the window.
 // Generates a new texture and uploads the texture data. The format of the
-// texture data must match what is hard coded here. Returns the ID for the
-// newly generated texture object.
-//
-// TODO: Can texture be created without uploading data?
+// texture data must match what is hard coded here: 4 bytes per pixel for
+// blue, green, red and an unused byte, which is what graphics hardware usually
+// stores internally, so no conversion is needed when uploading. Returns the ID
+// for the newly generated texture object.
 GLuint createTex(GLFWwindow* window,
                  GLvoid* data,
                  int width,
@@ -93,62 +100,131 @@ GLuint createTex(GLFWwindow* window,
   glBindTexture(GL_TEXTURE_2D, tex);
   glTexParameteri(GL_TEXTURE_2D, GL_TEXTURE_MIN_FILTER, GL_NEAREST);
   glTexParameteri(GL_TEXTURE_2D, GL_TEXTURE_MAG_FILTER, GL_NEAREST);
-  glPixelStorei(GL_UNPACK_ALIGNMENT, 1);
-  glPixelStorei(GL_PACK_ALIGNMENT, 1);
+  glPixelStorei(GL_UNPACK_ALIGNMENT, 4);
   glTexImage2D(GL_TEXTURE_2D,
                0,
-               GL_RGB8,
+               GL_RGBA8,
                (GLsizei)width,
                (GLsizei)height,
                0,
-               GL_RGB,
-               GL_UNSIGNED_BYTE,
+               GL_BGRA,
+               GL_UNSIGNED_INT_8_8_8_8_REV,
                data);
   return tex;
 }
 
-// Deletes a texture object.
+// Deletes a texture object of the window's context.
 void delTex(GLFWwindow* window,
             GLuint tex) {
+  glfwMakeContextCurrent(window);
   glDeleteTextures(1, &tex);
 }
 
-// Draws the texture with the given data.
-// Clears the framebuffer, sets an orthogonal projection to cover the whole
-// window content and draws a rectangle with the texture. The format of the
-// texture data must match what is hard coded here. The result won't be
-// shown on screen until buffers are swapped.
-void drawTex(GLFWwindow* window,
-             GLvoid* data,
-             int width,
-             int height) {
+// Creates a pixel buffer object to upload texture data through.
+// Returns its ID, or 0 if pixel buffer objects are not supported.
+GLuint createPbo(GLFWwindow* window) {
+  GLuint pbo;
+  glfwMakeContextCurrent(window);
+  if (!GLEW_VERSION_2_1 && !GLEW_ARB_pixel_buffer_object) {
+    return 0;
+  }
+  glGenBuffers(1, &pbo);
+  return pbo;
+}
+
+// Deletes a pixel buffer object, if any.
+void delPbo(GLFWwindow* window,
+            GLuint pbo) {
+  if (pbo == 0) {
+    return;
+  }
+  glfwMakeContextCurrent(window);
+  glDeleteBuffers(1, &pbo);
+}
+
+// Uploads the rectangle from (x0,y0) to (x1,y1), excluding the latter, of the
+// texture data with the given width to the texture.
+// With a pixel buffer object the rows of the rectangle are copied into the
+// buffer, from where the driver can transfer them asynchronously while the
+// program continues. The buffer's storage is orphaned first, so the copy
+// doesn't wait for the previous transfer to finish. Persistently mapped
+// buffers would save the copy, but need OpenGL 4.4 and fences to not
+// overwrite data still being read. Without a pixel buffer object the data is
+// uploaded directly.
+void uploadTex(GLFWwindow* window,
+               GLuint pbo,
+               unsigned char* data,
+               int width,
+               int x0,
+               int y0,
+               int x1,
+               int y1) {
+  GLsizeiptr size;
+  void* dst = NULL;
+  if (x1 <= x0 || y1 <= y0) {
+    return;
+  }
   glfwMakeContextCurrent(window);
-  glPixelStorei(GL_UNPACK_ALIGNMENT, 1);
-  glPixelStorei(GL_PACK_ALIGNMENT, 1);
+  glPixelStorei(GL_UNPACK_ALIGNMENT, 4);
+  glPixelStorei(GL_UNPACK_ROW_LENGTH, width);
+  glPixelStorei(GL_UNPACK_SKIP_PIXELS, x0);
+  if (pbo != 0) {
+    size = (GLsizeiptr)4 * width * (y1 - y0);
+    glBindBuffer(GL_PIXEL_UNPACK_BUFFER, pbo);
+    glBufferData(GL_PIXEL_UNPACK_BUFFER, size, NULL, GL_STREAM_DRAW);
+    dst = glMapBuffer(GL_PIXEL_UNPACK_BUFFER, GL_WRITE_ONLY);
+  }
+  if (dst != NULL) {
+    memcpy(dst, data + (size_t)4 * width * y0, size);
+    glUnmapBuffer(GL_PIXEL_UNPACK_BUFFER);
+    glPixelStorei(GL_UNPACK_SKIP_ROWS, 0);
+    data = NULL;
+  } else {
+    if (pbo != 0) {
+      glBindBuffer(GL_PIXEL_UNPACK_BUFFER, 0);
+    }
+    glPixelStorei(GL_UNPACK_SKIP_ROWS, y0);
+  }
+  // With a bound buffer the data pointer is an offset into it
   glTexSubImage2D(GL_TEXTURE_2D,
                   0,
-                  0,
-                  0,
-                  (GLsizei)width,
-                  (GLsizei)height,
-                  GL_RGB,
-                  GL_UNSIGNED_BYTE,
+                  x0,
+                  y0,
+                  (GLsizei)(x1 - x0),
+                  (GLsizei)(y1 - y0),
+                  GL_BGRA,
+                  GL_UNSIGNED_INT_8_8_8_8_REV,
                   data);
+  if (dst != NULL) {
+    glBindBuffer(GL_PIXEL_UNPACK_BUFFER, 0);
+  }
+  glPixelStorei(GL_UNPACK_ROW_LENGTH, 0);
+  glPixelStorei(GL_UNPACK_SKIP_PIXELS, 0);
+  glPixelStorei(GL_UNPACK_SKIP_ROWS, 0);
+}
+
+// Draws the texture.
+// Clears the framebuffer, sets an orthogonal projection to cover the whole
+// window content and draws a rectangle with the texture, scaled to fill the
+// viewport whatever the texture size. The result won't be shown on screen
+// until buffers are swapped.
+void drawTex(GLFWwindow* window) {
+  glfwMakeContextCurrent(window);
   glClearColor(0.0, 0.0, 0.0, 0.0);
   glClear(GL_COLOR_BUFFER_BIT);
   glMatrixMode(GL_PROJECTION);
   glLoadIdentity();
-  glOrtho(0.0, (GLdouble)(width-1), 0.0, (GLdouble)(height-1), -1.0, 1.0);
+  glOrtho(0.0, 1.0, 0.0, 1.0, -1.0, 1.0);
   glMatrixMode(GL_MODELVIEW);
   glLoadIdentity();
   glEnable(GL_TEXTURE_2D);
   glBegin(GL_QUADS);
   glTexCoord2i(0, 0);
-  glVertex2i(0, height-1);
+  glVertex2i(0, 1);
   glTexCoord2i(1, 0);
-  glVertex2i(width-1, height-1);
+  glVertex2i(1, 1);
   glTexCoord2i(1, 1);
-  glVertex2i(width-1, 0);
+  glVertex2i(1, 0);
   glTexCoord2i(0, 1);
   glVertex2i(0, 0);
   glEnd();
@@ -167,3 +243,34 @@ GLuint resizeTex(GLFWwindow* win,
   delTex(win, tex);
   return newTex;
 }
+
+// The thread Main runs on.
+static pthread_t mainThread;
+
+// Remember the current thread as the main thread.
+void setMainThread() {
+  mainThread = pthread_self();
+}
+
+// Check if the current thread is the main thread.
+// Returns 1 if so and otherwise 0.
+int isMainThread() {
+  return pthread_equal(mainThread, pthread_self()) ? 1 : 0;
+}
+
+// Sets the window icon from 8 bit RGBA pixels, not premultiplied.
+// Resets to the default icon if pixels is NULL.
+void setIcon(GLFWwindow* win,
+             unsigned char* pixels,
+             int width,
+             int height) {
+  GLFWimage img;
+  if (pixels == NULL) {
+    glfwSetWindowIcon(win, 0, NULL);
+    return;
+  }
+  img.width = width;
+  img.height = height;
+  img.pixels = pixels;
+  glfwSetWindowIcon(win, 1, &img);
+}
@@ -3,6 +3,7 @@ package window
 /*
 #cgo pkg-config: glew glfw3
 
+#include <stdlib.h>
 #include <GL/glew.h>
 #include <GLFW/glfw3.h>
 #include "window.h"
@@ -11,23 +12,49 @@ import "C"
 
 import (
 	"errors"
+	"github.com/amsibamsi/three/draw"
+	"github.com/amsibamsi/three/image"
 	tmath "github.com/amsibamsi/three/math"
 	"github.com/amsibamsi/three/math/geom"
+	stdimage "image"
+	"image/color"
+	"io"
+	"math"
 	"runtime"
 	"unsafe"
 )
 
 var (
-	// To track GLFW initialization that only needs to be done once.
+	// Whether GLFW is initialized
 	glfwInitDone = false
+
+	// Number of windows using GLFW. It is initialized for the first window and
+	// terminated after the last one was destroyed.
+	glfwRefs = 0
 )
 
 const (
-	KeyQ = C.GLFW_KEY_Q
-	KeyW = C.GLFW_KEY_W
-	KeyS = C.GLFW_KEY_S
-	KeyA = C.GLFW_KEY_A
-	KeyD = C.GLFW_KEY_D
+	Key0   = C.GLFW_KEY_0
+	Key1   = C.GLFW_KEY_1
+	Key2   = C.GLFW_KEY_2
+	Key3   = C.GLFW_KEY_3
+	Key4   = C.GLFW_KEY_4
+	Key5   = C.GLFW_KEY_5
+	Key6   = C.GLFW_KEY_6
+	Key7   = C.GLFW_KEY_7
+	Key8   = C.GLFW_KEY_8
+	Key9   = C.GLFW_KEY_9
+	KeyQ   = C.GLFW_KEY_Q
+	KeyW   = C.GLFW_KEY_W
+	KeyE   = C.GLFW_KEY_E
+	KeyS   = C.GLFW_KEY_S
+	KeyA   = C.GLFW_KEY_A
+	KeyD   = C.GLFW_KEY_D
+	KeyF12 = C.GLFW_KEY_F12
+
+	KeySpace     = C.GLFW_KEY_SPACE
+	KeyLeftShift = C.GLFW_KEY_LEFT_SHIFT
+	KeyEscape    = C.GLFW_KEY_ESCAPE
 )
 
 // initGlfw initializes windowing by initializing GLFW. The current goroutine
@@ -42,26 +69,61 @@ func initGlfw() error {
 	return nil
 }
 
-// ensGlfwInit ensures GLFW has been initialized. It calls initGlfw() if not
-// yet done so.
-func ensGlfwInit() error {
+// ensGlfw ensures GLFW has been initialized. It calls initGlfw() if not yet
+// done so.
+func ensGlfw() error {
 	if glfwInitDone {
 		return nil
-	} else {
-		return initGlfw()
+	}
+	if err := initGlfw(); err != nil {
+		return err
+	}
+	glfwInitDone = true
+	return nil
+}
+
+// termGlfw terminates GLFW if it is initialized.
+func termGlfw() {
+	if glfwInitDone {
+		C.glfwTerminate()
+		glfwInitDone = false
+	}
+}
+
+// acquireGlfw registers a new window using GLFW and ensures GLFW is
+// initialized.
+func acquireGlfw() error {
+	if err := ensGlfw(); err != nil {
+		return err
+	}
+	glfwRefs++
+	return nil
+}
+
+// releaseGlfw unregisters a window using GLFW. GLFW is terminated after the
+// last one.
+func releaseGlfw() {
+	glfwRefs--
+	if glfwRefs == 0 {
+		termGlfw()
 	}
 }
 
 // newTex creates a new byte slice that holds the texture data.
 func newTex(w, h int) []byte {
-	return make([]byte, 3*w*h)
+	return make([]byte, 4*w*h)
 }
 
 // Terminate destroys and cleans up all remaining windows and terminates
 // windowing. Should be called at the end of a program or when no more
-// windowing is needed.
+// windowing is needed. New windows can still be created afterwards.
 func Terminate() {
-	C.glfwTerminate()
+	Do(func() {
+		for _, w := range windows {
+			w.destroy()
+		}
+		termGlfw()
+	})
 }
 
 // pollEvents registers pending event input and makes it ready to be queried.
@@ -72,83 +134,220 @@ func pollEvents() {
 // Window represents a graphical window.
 type Window struct {
 
-	// Width of the window content
+	// Width of the window content in pixels as drawn to, the resolution of
+	// the texture
 	width int
 
-	// Height of the window content
+	// Height of the window content in pixels as drawn to
 	height int
 
+	// Size of the window content in screen coordinates
+	winWidth, winHeight int
+
+	// Size of the window content in pixels on screen. Differs from the size in
+	// screen coordinates on scaled (HiDPI) displays.
+	fbWidth, fbHeight int
+
+	// Resolution of the content if fixed, 0 if following the framebuffer
+	resWidth, resHeight int
+
+	// Resolution of the content relative to the framebuffer if not fixed
+	resScale float64
+
 	// The actual window, a GLFW window
 	glfwWin *C.GLFWwindow
 
 	// Texture ID from OpenGL to draw the content to
 	texId C.GLuint
 
-	// Texture data. The format is based on OpenGL: 3 consecutive bytes build the
-	// color for 1 pixel with red/green/blue values. Pixels are mapped to the
+	// Texture data. The format is based on OpenGL: 4 consecutive bytes build the
+	// color for 1 pixel with blue/green/red values and an unused byte, which
+	// graphics hardware takes without conversion. Pixels are mapped to the
 	// screen from left to right and top to bottom. So the texture starts at the
 	// top left, first continues to the right and then breaks lines towards the
 	// bottom.
 	tex []byte
+
+	// Pixel buffer object to upload the texture through, 0 if not supported
+	pbo C.GLuint
+
+	// Area of the texture data changed since the last upload
+	dirty stdimage.Rectangle
+
+	// Scroll offset between the last two updates, and since the last update
+	scroll     [2]float64
+	scrollNext [2]float64
+
+	// Position and size before going fullscreen
+	windowed [4]C.int
 }
 
-// NewWindow returns a new window. It initializes GLFW and GLEW, creates a new
-// GLFW window with given width, height and title, initializes the texture
-// data.
+// NewWindow returns a new window with default options but the given width,
+// height, title and visibility.
 func NewWindow(
 	width, height int,
 	title string,
 	visible bool,
 ) (*Window, error) {
-	if width < 0 {
+	o := DefOptions()
+	o.Width = width
+	o.Height = height
+	o.Title = title
+	o.Visible = visible
+	return NewWindowOptions(o)
+}
+
+// NewWindowOptions returns a new window configured by the given options. It
+// initializes GLFW and GLEW, creates a new GLFW window and initializes the
+// texture data.
+func NewWindowOptions(o *Options) (w *Window, err error) {
+	Do(func() {
+		w, err = newWindow(o)
+	})
+	return
+}
+
+// glfwBool returns the GLFW boolean value for b.
+func glfwBool(b bool) C.int {
+	if b {
+		return C.GL_TRUE
+	}
+	return C.GL_FALSE
+}
+
+// newWindow creates a new window as described for NewWindowOptions on the
+// current thread.
+func newWindow(o *Options) (*Window, error) {
+	if o.Width < 0 {
 		return nil, errors.New("Width must not be < 0")
 	}
-	if height < 0 {
+	if o.Height < 0 {
 		return nil, errors.New("Height must not be < 0")
 	}
-	err := ensGlfwInit()
+	err := acquireGlfw()
 	if err != nil {
 		return nil, err
 	}
-	var v = 0
-	if visible {
-		v = 1
+	width, height := o.Width, o.Height
+	var monitor *C.GLFWmonitor
+	C.glfwDefaultWindowHints()
+	if o.Monitor != nil {
+		monitor = o.Monitor.m
+		mode := C.glfwGetVideoMode(monitor)
+		if mode == nil {
+			releaseGlfw()
+			return nil, errVideoMode
+		}
+		if o.Borderless || width == 0 || height == 0 {
+			width, height = int(mode.width), int(mode.height)
+		}
+		if o.Borderless {
+			C.glfwWindowHint(C.GLFW_RED_BITS, mode.redBits)
+			C.glfwWindowHint(C.GLFW_GREEN_BITS, mode.greenBits)
+			C.glfwWindowHint(C.GLFW_BLUE_BITS, mode.blueBits)
+			C.glfwWindowHint(C.GLFW_REFRESH_RATE, mode.refreshRate)
+		}
 	}
+	// Shown only after being positioned
+	C.glfwWindowHint(C.GLFW_VISIBLE, C.GL_FALSE)
+	C.glfwWindowHint(C.GLFW_RESIZABLE, glfwBool(o.Resizable))
+	C.glfwWindowHint(C.GLFW_DECORATED, glfwBool(o.Decorated))
+	ctitle := C.CString(o.Title)
+	defer C.free(unsafe.Pointer(ctitle))
 	glfwWin := C.createWin(
 		C.int(width),
 		C.int(height),
-		C.CString(title),
-		C.int(v),
+		ctitle,
+		monitor,
 	)
 	if glfwWin == nil {
-		Terminate()
+		releaseGlfw()
 		return nil, errors.New("Failed to create window")
 	}
 	errno := int(C.initGlew(glfwWin))
 	if errno != 1 {
+		C.glfwDestroyWindow(glfwWin)
+		releaseGlfw()
 		return nil, errors.New("Failed to init GLEW")
 	}
-	C.initWin(glfwWin, C.int(width), C.int(height))
-	tex := newTex(width, height)
-	texId := C.createTex(
-		glfwWin,
-		unsafe.Pointer(&tex),
-		C.int(width),
-		C.int(height),
-	)
-	C.glfwSetInputMode(glfwWin, C.GLFW_CURSOR, C.GLFW_CURSOR_DISABLED)
-	return &Window{width, height, glfwWin, texId, tex}, nil
+	if o.Pos != nil && monitor == nil {
+		C.glfwSetWindowPos(glfwWin, C.int(o.Pos[0]), C.int(o.Pos[1]))
+	}
+	if o.Visible {
+		C.glfwShowWindow(glfwWin)
+	}
+	C.setSwapInterval(glfwWin, C.int(o.Interval))
+	cursor := C.GLFW_CURSOR_NORMAL
+	if o.CaptureCursor {
+		cursor = C.GLFW_CURSOR_DISABLED
+	}
+	C.glfwSetInputMode(glfwWin, C.GLFW_CURSOR, C.int(cursor))
+	C.setCallbacks(glfwWin)
+	w := &Window{
+		glfwWin:  glfwWin,
+		texId:    C.createTex(glfwWin, nil, 0, 0),
+		pbo:      C.createPbo(glfwWin),
+		resScale: 1,
+	}
+	// The size may differ from the one asked for, e.g. in fullscreen
+	w.resize()
+	windows[glfwWin] = w
+	return w, nil
+}
+
+// texData returns a pointer to the texture data for OpenGL, or nil if there is
+// none.
+func texData(tex []byte) unsafe.Pointer {
+	if len(tex) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&tex[0])
 }
 
-// redraw draws the current texture data to the window. The content will first
-// be shown on screen when the window is updated.
+// touch marks the rectangle from (x0,y0) to (x1,y1), excluding the latter, of
+// the texture data as changed so that it is uploaded on the next redraw.
+func (w *Window) touch(x0, y0, x1, y1 int) {
+	d := &w.dirty
+	if d.Empty() {
+		*d = stdimage.Rect(x0, y0, x1, y1)
+		return
+	}
+	if x0 < d.Min.X {
+		d.Min.X = x0
+	}
+	if y0 < d.Min.Y {
+		d.Min.Y = y0
+	}
+	if x1 > d.Max.X {
+		d.Max.X = x1
+	}
+	if y1 > d.Max.Y {
+		d.Max.Y = y1
+	}
+}
+
+// redraw uploads the changed texture data and draws the texture to the window.
+// The content will first be shown on screen when the window is updated. The
+// pixel buffer object is only used for partial uploads, a full upload directly
+// from the texture data saves copying it into the buffer first.
 func (w *Window) redraw() {
-	C.drawTex(
+	d := w.dirty
+	pbo := w.pbo
+	if d == stdimage.Rect(0, 0, w.width, w.height) {
+		pbo = 0
+	}
+	C.uploadTex(
 		w.glfwWin,
-		unsafe.Pointer(&w.tex[0]),
+		pbo,
+		(*C.uchar)(texData(w.tex)),
 		C.int(w.width),
-		C.int(w.height),
+		C.int(d.Min.X),
+		C.int(d.Min.Y),
+		C.int(d.Max.X),
+		C.int(d.Max.Y),
 	)
+	w.dirty = stdimage.Rectangle{}
+	C.drawTex(w.glfwWin)
 }
 
 // refreshWait refreshes the window content on screen with the currently drawn
@@ -157,17 +356,32 @@ func (w *Window) refreshWait() {
 	C.glfwSwapBuffers(w.glfwWin)
 }
 
+// resolution returns the width and height of the window content in pixels as
+// drawn to, at least 1x1 so there is always a texture.
+func (w *Window) resolution() (int, int) {
+	if w.resWidth > 0 && w.resHeight > 0 {
+		return w.resWidth, w.resHeight
+	}
+	width := int(float64(w.fbWidth)*w.resScale + 0.5)
+	height := int(float64(w.fbHeight)*w.resScale + 0.5)
+	return tmath.Maxi(width, 1), tmath.Maxi(height, 1)
+}
+
 // resize adapts the window and texture content to the current size of the
 // window. It should be called periodically to adapt to GUI changes to the
-// window. It checks the new window dimensions and if necessary creates a new
-// texture with new size. Previously drawn content will be lost.
+// window. It checks the new window and framebuffer dimensions and if
+// necessary creates a new texture with new size. Previously drawn content will
+// be lost.
 func (w *Window) resize() {
-	var width, height int
-	C.glfwGetWindowSize(
-		w.glfwWin,
-		(*C.int)(unsafe.Pointer(&width)),
-		(*C.int)(unsafe.Pointer(&height)),
-	)
+	var ww, wh, fw, fh C.int
+	C.glfwGetWindowSize(w.glfwWin, &ww, &wh)
+	C.glfwGetFramebufferSize(w.glfwWin, &fw, &fh)
+	w.winWidth, w.winHeight = int(ww), int(wh)
+	if int(fw) != w.fbWidth || int(fh) != w.fbHeight {
+		w.fbWidth, w.fbHeight = int(fw), int(fh)
+		C.winResized(w.glfwWin, fw, fh)
+	}
+	width, height := w.resolution()
 	if width != w.width || height != w.height {
 		w.width = width
 		w.height = height
@@ -175,35 +389,91 @@ func (w *Window) resize() {
 		w.texId = C.resizeTex(
 			w.glfwWin,
 			w.texId,
-			unsafe.Pointer(&w.tex[0]),
+			texData(w.tex),
 			C.int(width),
 			C.int(height),
 		)
-		C.winResized(w.glfwWin, C.int(width), C.int(height))
+		// Uploaded when created
+		w.dirty = stdimage.Rectangle{}
+	}
+}
+
+// SetResolution fixes the resolution of the window content to the given width
+// and height in pixels, independent of the window size. The content is scaled
+// to fill the window without filtering, so a low resolution looks blocky. 0
+// lets the resolution follow the window size again. Returns an error if width
+// or height is negative.
+func (w *Window) SetResolution(width, height int) error {
+	if width < 0 {
+		return errors.New("Width must not be < 0")
+	}
+	if height < 0 {
+		return errors.New("Height must not be < 0")
+	}
+	Do(func() {
+		w.resWidth, w.resHeight = width, height
+		w.resize()
+	})
+	return nil
+}
+
+// SetResolutionScale lets the resolution of the window content follow the
+// window size in pixels scaled by s, e.g. 0.5 for half the resolution. The
+// content is scaled to fill the window like with SetResolution. The default is
+// 1, the full resolution of the screen. It has no effect while the resolution
+// is fixed. The resolution is at least 1x1 pixels. Returns an error if s is
+// not > 0 and finite.
+func (w *Window) SetResolutionScale(s float64) error {
+	if !(s > 0) || math.IsInf(s, 1) {
+		return errors.New("Scale must be > 0 and finite")
 	}
+	Do(func() {
+		w.resScale = s
+		w.resize()
+	})
+	return nil
+}
+
+// WindowSize returns the size of the window content in screen coordinates as
+// of the last update. Window positions and sizes given to the system are in
+// screen coordinates.
+func (w *Window) WindowSize() (int, int) {
+	return w.winWidth, w.winHeight
+}
+
+// FramebufferSize returns the size of the window content in pixels on screen
+// as of the last update. On scaled (HiDPI) displays it is larger than the
+// size in screen coordinates.
+func (w *Window) FramebufferSize() (int, int) {
+	return w.fbWidth, w.fbHeight
 }
 
 // Set works like Setxy, but for vectors.
 func (w *Window) Set(v *geom.Vec2, r, g, b byte) {
-	if v[0] < 0 || v[0] > w.width || v[1] < 0 || v[1] > w.height {
-		return
-	}
-	i := v[1]*3*w.width + v[0]*3
-	w.tex[i] = r
-	w.tex[i+1] = g
-	w.tex[i+2] = b
+	w.Setxy(v[0], v[1], r, g, b)
 }
 
 // Setxy sets the texture color at the given position. If (x,y) lies not within
 // the window nothing is drawn.
 func (w *Window) Setxy(x, y int, r, g, b byte) {
-	if x < 0 || x > w.width || y < 0 || y > w.height {
+	if x < 0 || x >= w.width || y < 0 || y >= w.height {
 		return
 	}
-	i := y*3*w.width + x*3
-	w.tex[i] = r
+	i := 4 * (y*w.width + x)
+	w.tex[i] = b
 	w.tex[i+1] = g
-	w.tex[i+2] = b
+	w.tex[i+2] = r
+	w.touch(x, y, x+1, y+1)
+}
+
+// Getxy returns the texture color at the given position. If (x,y) lies not
+// within the window it returns black.
+func (w *Window) Getxy(x, y int) (byte, byte, byte) {
+	if x < 0 || x >= w.width || y < 0 || y >= w.height {
+		return 0, 0, 0
+	}
+	i := 4 * (y*w.width + x)
+	return w.tex[i+2], w.tex[i+1], w.tex[i]
 }
 
 // Dot draws visible dot at the given coordinates. It's bigger than just one
@@ -224,27 +494,7 @@ func (w *Window) Dot(v *geom.Vec2, r, g, b byte) {
 
 // Line draws a line between two points. It's 1 pixel thick.
 func (w *Window) Line(v1, v2 *geom.Vec2, r, g, b byte) {
-	// Always draw from left to right (x1 <= x2)
-	if v1[0] > v2[0] {
-		v1, v2 = v2, v1
-	}
-	dx := v2[0] - v1[0]
-	dy := v2[1] - v1[1]
-	var steps int
-	if tmath.Absi(dx) > tmath.Absi(dy) {
-		steps = dx
-	} else {
-		steps = tmath.Absi(dy)
-	}
-	xinc := float64(dx) / float64(steps)
-	yinc := float64(dy) / float64(steps)
-	x := float64(v1[0])
-	y := float64(v1[1])
-	for s := 0; s <= steps; s++ {
-		w.Setxy(tmath.Round(x), tmath.Round(y), r, g, b)
-		x += xinc
-		y += yinc
-	}
+	draw.Line(w.Canvas(), v1[0], v1[1], v2[0], v2[1], color.RGBA{r, g, b, 255})
 }
 
 // Key is a wrapper for GLFW key codes.
@@ -253,7 +503,10 @@ type Key C.int
 // KeyDown returns true if the corresponding key is currently (since the last
 // polling of events) pressed down. Otherwise it returns false.
 func (w *Window) KeyDown(k Key) bool {
-	state := C.glfwGetKey(w.glfwWin, C.int(k))
+	var state C.int
+	Do(func() {
+		state = C.glfwGetKey(w.glfwWin, C.int(k))
+	})
 	return (state == C.GLFW_PRESS)
 }
 
@@ -264,10 +517,135 @@ func (w *Window) KeyDown(k Key) bool {
 //   3. Adapts the window for any resizing
 //   4. Polls events and makes them ready for processing
 func (w *Window) Update() {
-	w.redraw()
-	w.refreshWait()
-	w.resize()
-	pollEvents()
+	Do(func() {
+		w.redraw()
+		w.refreshWait()
+		w.resize()
+		pollEvents()
+		w.scroll = w.scrollNext
+		w.scrollNext = [2]float64{}
+	})
+}
+
+// SetVsync switches V-Sync on or off. With V-Sync on Update waits for the
+// screen to refresh before showing new content, which is the default. With
+// V-Sync off content is shown as fast as possible.
+func (w *Window) SetVsync(on bool) {
+	i := 0
+	if on {
+		i = 1
+	}
+	Do(func() {
+		C.setSwapInterval(w.glfwWin, C.int(i))
+	})
+}
+
+// SetTitle changes the title shown in the window's title bar.
+func (w *Window) SetTitle(title string) {
+	ctitle := C.CString(title)
+	defer C.free(unsafe.Pointer(ctitle))
+	Do(func() {
+		C.glfwSetWindowTitle(w.glfwWin, ctitle)
+	})
+}
+
+// SetSize asks to resize the window content to the given width and height in
+// screen coordinates.
+// In fullscreen this changes the video mode. The window content adapts on the
+// next Update.
+func (w *Window) SetSize(width, height int) {
+	Do(func() {
+		C.glfwSetWindowSize(w.glfwWin, C.int(width), C.int(height))
+	})
+}
+
+// Pos returns the position of the window content's top left corner on the
+// virtual screen spanning all monitors.
+func (w *Window) Pos() (int, int) {
+	var x, y C.int
+	Do(func() {
+		C.glfwGetWindowPos(w.glfwWin, &x, &y)
+	})
+	return int(x), int(y)
+}
+
+// SetPos moves the window content's top left corner to the given position on
+// the virtual screen spanning all monitors. Fullscreen windows don't move.
+func (w *Window) SetPos(x, y int) {
+	Do(func() {
+		C.glfwSetWindowPos(w.glfwWin, C.int(x), C.int(y))
+	})
+}
+
+// SetIcon sets the icon of the window, e.g. shown in the title bar or task
+// bar, to the given image. The system scales it as needed, good sizes are
+// 16x16, 32x32 and 48x48 pixels. A nil image resets the default icon.
+func (w *Window) SetIcon(img stdimage.Image) {
+	if img == nil {
+		Do(func() {
+			C.setIcon(w.glfwWin, nil, 0, 0)
+		})
+		return
+	}
+	b := img.Bounds()
+	pix := make([]byte, 0, 4*b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pix = append(pix, c.R, c.G, c.B, c.A)
+		}
+	}
+	if len(pix) == 0 {
+		return
+	}
+	Do(func() {
+		C.setIcon(
+			w.glfwWin,
+			(*C.uchar)(unsafe.Pointer(&pix[0])),
+			C.int(b.Dx()),
+			C.int(b.Dy()),
+		)
+	})
+}
+
+// Monitor returns the monitor the window is fullscreen on, or nil for a normal
+// window.
+func (w *Window) Monitor() (m *Monitor) {
+	Do(func() {
+		if p := C.glfwGetWindowMonitor(w.glfwWin); p != nil {
+			m = &Monitor{p}
+		}
+	})
+	return
+}
+
+// SetFullscreen makes the window fullscreen on monitor m at its current video
+// mode. With nil it becomes a normal window again with the position and size
+// it had before. Returns an error if the monitor's video mode is unknown, e.g.
+// because it was disconnected.
+func (w *Window) SetFullscreen(m *Monitor) (err error) {
+	Do(func() {
+		full := C.glfwGetWindowMonitor(w.glfwWin) != nil
+		if m == nil {
+			if full {
+				p := &w.windowed
+				C.glfwSetWindowMonitor(w.glfwWin, nil, p[0], p[1], p[2], p[3], 0)
+			}
+			return
+		}
+		if !full {
+			p := &w.windowed
+			C.glfwGetWindowPos(w.glfwWin, &p[0], &p[1])
+			C.glfwGetWindowSize(w.glfwWin, &p[2], &p[3])
+		}
+		mode := C.glfwGetVideoMode(m.m)
+		if mode == nil {
+			err = errVideoMode
+			return
+		}
+		C.glfwSetWindowMonitor(w.glfwWin, m.m, 0, 0, mode.width, mode.height, mode.refreshRate)
+	})
+	return
 }
 
 // Clear clears the window content by setting all pixels to black.
@@ -275,33 +653,98 @@ func (w *Window) Clear() {
 	for i, _ := range w.tex {
 		w.tex[i] = 0
 	}
+	w.touch(0, 0, w.width, w.height)
 }
 
 // SetClose requests the window to close.
 func (w *Window) SetClose() {
-	C.glfwSetWindowShouldClose(w.glfwWin, C.GL_TRUE)
+	Do(func() {
+		C.glfwSetWindowShouldClose(w.glfwWin, C.GL_TRUE)
+	})
 }
 
 // ShouldClose returns true if the window was requested to close by a GUI
 // operation.
 func (w *Window) ShouldClose() bool {
-	should := C.glfwWindowShouldClose(w.glfwWin)
+	var should C.int
+	Do(func() {
+		should = C.glfwWindowShouldClose(w.glfwWin)
+	})
 	return should != 0
 }
 
-// Width returns the currently set width of the window. This may not be up to
-// date with the current GUI width of the window.
+// Resolve draws a float image to the window, e.g. the color of a rendered
+// framebuffer. Colors are tone mapped with t and encoded as sRGB. Only the area
+// covered by both the image and the window is drawn.
+func (w *Window) Resolve(f *image.FloatImage, t image.ToneMap) {
+	f.ResolveBgra(w.tex, w.width, w.height, t)
+	w.touch(0, 0, tmath.Mini(f.Width, w.width), tmath.Mini(f.Height, w.height))
+}
+
+// DrawBgra copies pixels stored as 4 consecutive bytes for blue, green, red and
+// alpha, like written by image.FloatImage.ResolveBgra, to the window. Bgra
+// holds width*height pixels from left to right and top to bottom. Only the
+// area covered by both bgra and the window is drawn. Resolving on another
+// goroutine and then only copying keeps the work on the main thread small.
+func (w *Window) DrawBgra(bgra []byte, width, height int) {
+	cw := tmath.Mini(width, w.width)
+	ch := tmath.Mini(height, w.height)
+	if cw <= 0 || ch <= 0 {
+		return
+	}
+	if width == w.width {
+		copy(w.tex, bgra[:4*width*ch])
+	} else {
+		for y := 0; y < ch; y++ {
+			copy(w.tex[4*y*w.width:], bgra[4*y*width:4*(y*width+cw)])
+		}
+	}
+	w.touch(0, 0, cw, ch)
+}
+
+// Image returns a new image with a copy of the current window content. This is
+// exactly what is shown on screen after the next Update.
+func (w *Window) Image() *image.Image {
+	return image.NewImageBgra(w.tex, w.width, w.height)
+}
+
+// WritePng stores the current window content in PNG format to the given writer
+// and returns the error from encoding if any.
+func (w *Window) WritePng(wr io.Writer) error {
+	return w.Image().WritePng(wr)
+}
+
+// Width returns the currently set width of the window content in pixels as
+// drawn to. This may not be up to date with the current GUI width of the
+// window, and differs from it with a fixed or scaled resolution.
 func (w *Window) Width() int {
 	return w.width
 }
 
-// Height returns the currently set height of the window. This may not be up to
-// date with the current GUI height of the window.
+// Height returns the currently set height of the window content in pixels as
+// drawn to. This may not be up to date with the current GUI height of the
+// window, and differs from it with a fixed or scaled resolution.
 func (w *Window) Height() int {
 	return w.height
 }
 
-// Destroy destroys the GLFW window.
+// Destroy destroys the window and releases its texture. Other windows stay
+// open. GLFW is terminated after the last window. The window must not be used
+// anymore afterwards, destroying it again does nothing.
 func (w *Window) Destroy() {
+	Do(w.destroy)
+}
+
+// destroy destroys the window as described for Destroy on the current thread.
+func (w *Window) destroy() {
+	if w.glfwWin == nil {
+		return
+	}
+	C.delTex(w.glfwWin, w.texId)
+	C.delPbo(w.glfwWin, w.pbo)
+	delete(windows, w.glfwWin)
 	C.glfwDestroyWindow(w.glfwWin)
+	w.glfwWin = nil
+	w.tex = nil
+	releaseGlfw()
 }
@@ -1,11 +1,19 @@
 void glfwError(int err, const char* desc);
 int initGlfw();
-GLFWwindow* createWin(int width, int height, char* title, int visible);
+GLFWwindow* createWin(int width, int height, char* title, GLFWmonitor* monitor);
+void setSwapInterval(GLFWwindow* win, int interval);
 int initGlew(GLFWwindow* win);
-void initWin(GLFWwindow* win, int width, int height);
+void setCallbacks(GLFWwindow* win);
 void winResized(GLFWwindow* win, int width, int height);
+void setIcon(GLFWwindow* win, unsigned char* pixels, int width, int height);
 
 GLuint createTex(GLFWwindow* window, GLvoid* data, int width, int height);
 void delTex(GLFWwindow* window, GLuint tex);
-void drawTex(GLFWwindow* window, GLvoid* data, int width, int height);
+GLuint createPbo(GLFWwindow* window);
+void delPbo(GLFWwindow* window, GLuint pbo);
+void uploadTex(GLFWwindow* window, GLuint pbo, unsigned char* data, int width, int x0, int y0, int x1, int y1);
+void drawTex(GLFWwindow* window);
 GLuint resizeTex(GLFWwindow* win, GLuint tex, GLvoid* data, int width, int height);
+
+void setMainThread();
+int isMainThread();
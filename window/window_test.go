@@ -0,0 +1,69 @@
+package window
+
+import (
+	"os"
+	"testing"
+)
+
+// Windows need the main thread, the tests run in Main.
+func TestMain(m *testing.M) {
+	code := 0
+	Main(func() {
+		code = m.Run()
+	})
+	Terminate()
+	os.Exit(code)
+}
+
+// benchWindow returns a hidden window with 1920x1080 pixels that updates
+// without waiting for the screen. Skips the benchmark if no window can be
+// created, e.g. without a display.
+func benchWindow(b *testing.B) *Window {
+	o := DefOptions()
+	o.Width = 1920
+	o.Height = 1080
+	o.Visible = false
+	o.Interval = 0
+	w, err := NewWindowOptions(o)
+	if err != nil {
+		b.Skip(err)
+	}
+	w.Update()
+	return w
+}
+
+// Content changes everywhere every frame, e.g. a rendered scene
+func BenchmarkUpdateFull(b *testing.B) {
+	w := benchWindow(b)
+	defer w.Destroy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Clear()
+		w.Update()
+	}
+}
+
+// Content changes only a little, e.g. a cursor
+func BenchmarkUpdateSmall(b *testing.B) {
+	w := benchWindow(b)
+	defer w.Destroy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				w.Setxy(x+i%100, y, 255, 255, 255)
+			}
+		}
+		w.Update()
+	}
+}
+
+// Content doesn't change, e.g. a paused view
+func BenchmarkUpdateUnchanged(b *testing.B) {
+	w := benchWindow(b)
+	defer w.Destroy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Update()
+	}
+}